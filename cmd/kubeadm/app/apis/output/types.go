@@ -0,0 +1,55 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package output定义kubeadm命令以--output=json|yaml打印结构化结果时使用的稳定schema，
+// 让Terraform、Ansible、cluster-api等自动化工具可以直接反序列化，而不必正则解析面向人类的文本输出。
+package output
+
+// PhaseStatus描述Workflow中一个阶段最终是被执行还是被跳过。
+type PhaseStatus struct {
+	// Name是阶段在Workflow中的完整路径名。
+	Name string `json:"name"`
+	// Status是该阶段的最终状态："executed"或"skipped"。
+	Status string `json:"status"`
+}
+
+const (
+	// PhaseStatusExecuted表示该阶段被执行了。
+	PhaseStatusExecuted = "executed"
+	// PhaseStatusSkipped表示该阶段因为--skip-phases或--phase过滤而被跳过。
+	PhaseStatusSkipped = "skipped"
+)
+
+// InitOutput是`kubeadm init --output=json|yaml`成功后打印的结构化文档，供自动化流水线消费，
+// 替代此前只能通过正则解析面向人类的文本模板来提取同样信息的做法。
+type InitOutput struct {
+	// KubeConfigPath是管理员kubeconfig文件在本机的路径。
+	KubeConfigPath string `json:"kubeConfigPath"`
+	// ControlPlaneEndpoint是该集群对外暴露的控制平面端点。
+	ControlPlaneEndpoint string `json:"controlPlaneEndpoint"`
+	// CACertHashes是CA证书公钥的Pin列表，加入集群的节点用它来验证CA证书。
+	CACertHashes []string `json:"caCertHashes"`
+	// BootstrapToken是用于加入集群的默认引导令牌。
+	BootstrapToken string `json:"bootstrapToken"`
+	// CertificateKey是用于加密上传的控制平面证书的密钥；未启用--upload-certs时为空。
+	CertificateKey string `json:"certificateKey,omitempty"`
+	// JoinControlPlaneCommand是加入一个新控制平面节点所需执行的完整命令。
+	JoinControlPlaneCommand string `json:"joinControlPlaneCommand,omitempty"`
+	// JoinWorkerCommand是加入一个新worker节点所需执行的完整命令。
+	JoinWorkerCommand string `json:"joinWorkerCommand"`
+	// Phases是本次运行中每一个阶段的最终执行状态。
+	Phases []PhaseStatus `json:"phases"`
+}
@@ -96,6 +96,7 @@ func NewKubeadmCommand(in io.Reader, out, err io.Writer) *cobra.Command {
 	cmds.AddCommand(alpha.NewCmdAlpha())
 	options.AddKubeadmOtherFlags(cmds.PersistentFlags(), &rootfsPath)
 	cmds.AddCommand(newCmdKubeConfigUtility(out))
+	cmds.AddCommand(newCmdKubeletConfig(out))
 	// end:挂载子命令
 
 	return cmds
@@ -1,27 +1,37 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"text/template"
+	"time"
 
 	kubeadmapi "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm"
 	kubeadmscheme "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm/scheme"
 	kubeadmapiv1 "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm/v1beta3"
 	"k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm/validation"
+	outputapi "k8s.io/kubernetes/cmd/kubeadm/app/apis/output"
 	"k8s.io/kubernetes/cmd/kubeadm/app/cmd/options"
 	phases "k8s.io/kubernetes/cmd/kubeadm/app/cmd/phases/init"
 	"k8s.io/kubernetes/cmd/kubeadm/app/cmd/phases/workflow"
 	cmdutil "k8s.io/kubernetes/cmd/kubeadm/app/cmd/util"
 	kubeadmconstants "k8s.io/kubernetes/cmd/kubeadm/app/constants"
 	"k8s.io/kubernetes/cmd/kubeadm/app/features"
+	"k8s.io/kubernetes/cmd/kubeadm/app/phases/addons/cni"
 	certsphase "k8s.io/kubernetes/cmd/kubeadm/app/phases/certs"
+	cniphase "k8s.io/kubernetes/cmd/kubeadm/app/phases/cni"
 	kubeconfigphase "k8s.io/kubernetes/cmd/kubeadm/app/phases/kubeconfig"
+	"k8s.io/kubernetes/cmd/kubeadm/app/preflight"
 	"k8s.io/kubernetes/cmd/kubeadm/app/util/apiclient"
 	configutil "k8s.io/kubernetes/cmd/kubeadm/app/util/config"
+	"k8s.io/kubernetes/cmd/kubeadm/app/util/initjournal"
 	kubeconfigutil "k8s.io/kubernetes/cmd/kubeadm/app/util/kubeconfig"
+	pkiutiltesting "k8s.io/kubernetes/cmd/kubeadm/app/util/pkiutil/testing"
+	"k8s.io/kubernetes/cmd/kubeadm/app/util/signer"
 
 	"k8s.io/apimachinery/pkg/util/sets"
 	clientset "k8s.io/client-go/kubernetes"
@@ -30,6 +40,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	flag "github.com/spf13/pflag"
+	"sigs.k8s.io/yaml"
 )
 
 var (
@@ -75,19 +86,42 @@ var (
 // initOptions 定义kubeadm init通过标志公开的所有init选项。
 // 请注意，此结构包括公共kubeadm config API，但此API支持的选项中只有一个子集将作为标志公开。
 type initOptions struct {
-	cfgPath                 string
-	skipTokenPrint          bool
-	dryRun                  bool
-	kubeconfigDir           string
-	kubeconfigPath          string
-	featureGatesString      string
-	ignorePreflightErrors   []string
-	bto                     *options.BootstrapTokenOptions
-	externalInitCfg         *kubeadmapiv1.InitConfiguration
-	externalClusterCfg      *kubeadmapiv1.ClusterConfiguration
-	uploadCerts             bool
-	skipCertificateKeyPrint bool
-	patchesDir              string
+	cfgPath                         string
+	skipTokenPrint                  bool
+	dryRun                          bool
+	kubeconfigDir                   string
+	kubeconfigPath                  string
+	featureGatesString              string
+	ignorePreflightErrors           []string
+	bto                             *options.BootstrapTokenOptions
+	externalInitCfg                 *kubeadmapiv1.InitConfiguration
+	externalClusterCfg              *kubeadmapiv1.ClusterConfiguration
+	uploadCerts                     bool
+	skipCertificateKeyPrint         bool
+	patchesDir                      string
+	joinOutputFormat                string
+	imagePullParallelism            int
+	imagePullRetries                int
+	imagePullTimeout                time.Duration
+	dryRunDiff                      bool
+	preflightPluginDir              string
+	waitEventsFile                  string
+	podNetworkAddon                 string
+	podNetworkAddonConfig           string
+	cniConfDir                      string
+	cniBinDir                       string
+	strictCNI                       bool
+	stateDir                        string
+	resume                          bool
+	restartFrom                     string
+	restart                         bool
+	signerSocket                    string
+	signerType                      string
+	caKeyProviderURI                string
+	autoApproveKubeletServing       bool
+	rotateKubeletServerCertOnExpiry bool
+	kubeConfigSigner                string
+	testPKIFixtures                 bool
 }
 
 // 编译时断言本地数据对象满足阶段数据接口。
@@ -96,20 +130,41 @@ var _ phases.InitData = &initData{}
 // initData 定义运行kubeadm init Workflow时使用的所有运行时信息；
 // 此数据在Workflow中包含的所有阶段中共享。
 type initData struct {
-	cfg                     *kubeadmapi.InitConfiguration
-	skipTokenPrint          bool
-	dryRun                  bool
-	kubeconfigDir           string
-	kubeconfigPath          string
-	ignorePreflightErrors   sets.String
-	certificatesDir         string
-	dryRunDir               string
-	externalCA              bool
-	client                  clientset.Interface
-	outputWriter            io.Writer
-	uploadCerts             bool
-	skipCertificateKeyPrint bool
-	patchesDir              string
+	cfg                             *kubeadmapi.InitConfiguration
+	skipTokenPrint                  bool
+	dryRun                          bool
+	kubeconfigDir                   string
+	kubeconfigPath                  string
+	ignorePreflightErrors           sets.String
+	certificatesDir                 string
+	dryRunDir                       string
+	externalCA                      bool
+	client                          clientset.Interface
+	outputWriter                    io.Writer
+	uploadCerts                     bool
+	skipCertificateKeyPrint         bool
+	patchesDir                      string
+	joinOutputFormat                string
+	imagePullParallelism            int
+	imagePullRetries                int
+	imagePullTimeout                time.Duration
+	dryRunDiff                      bool
+	preflightPluginDir              string
+	waitEventsFile                  string
+	podNetworkAddon                 string
+	podNetworkAddonConfig           string
+	cniConfDir                      string
+	cniBinDir                       string
+	strictCNI                       bool
+	stateDir                        string
+	resume                          bool
+	restartFrom                     string
+	restart                         bool
+	signerSocket                    string
+	signerType                      string
+	caKeyProviderURI                string
+	autoApproveKubeletServing       bool
+	rotateKubeletServerCertOnExpiry bool
 }
 
 // newCmdInit 返回kubeadm init命令
@@ -147,8 +202,14 @@ func newCmdInit(out io.Writer, initOptions *initOptions) *cobra.Command {
 				return err
 			}
 
+			// 计算每个阶段最终的执行状态（executed/skipped），供--output=json|yaml的结构化输出使用
+			phaseStatuses, err := initRunner.PhaseStatuses()
+			if err != nil {
+				return err
+			}
+
 			// 打印kubeadm join的链接
-			return showJoinCommand(data, out)
+			return showJoinCommand(data, phaseStatuses, out)
 		},
 		Args: cobra.NoArgs,
 	}
@@ -183,11 +244,13 @@ func newCmdInit(out io.Writer, initOptions *initOptions) *cobra.Command {
 	})
 
 	// 使用不同阶段(Phase)的任务初始化Workflow的Runner
-	fmt.Println("执行: cmd/kubeadm/app/cmd/init.go[newCmdInit][AppendPhase] x 13")
+	fmt.Println("执行: cmd/kubeadm/app/cmd/init.go[newCmdInit][AppendPhase] x 16")
 	initRunner.AppendPhase(phases.NewPreflightPhase())
+	initRunner.AppendPhase(phases.NewImagePrepullPhase())
 	initRunner.AppendPhase(phases.NewCertsPhase())
 	initRunner.AppendPhase(phases.NewKubeConfigPhase())
 	initRunner.AppendPhase(phases.NewKubeletStartPhase())
+	initRunner.AppendPhase(phases.NewCheckCNIPhase())
 	initRunner.AppendPhase(phases.NewControlPlanePhase())
 	initRunner.AppendPhase(phases.NewEtcdPhase())
 	initRunner.AppendPhase(phases.NewWaitControlPlanePhase())
@@ -197,6 +260,7 @@ func newCmdInit(out io.Writer, initOptions *initOptions) *cobra.Command {
 	initRunner.AppendPhase(phases.NewBootstrapTokenPhase())
 	initRunner.AppendPhase(phases.NewKubeletFinalizePhase())
 	initRunner.AppendPhase(phases.NewAddonPhase())
+	initRunner.AppendPhase(phases.NewPodNetworkPhase())
 
 	// 设置数据生成器函数, 在Runner整个Workflow或者单个阶段上都使用该函数
 	fmt.Println("执行: cmd/kubeadm/app/cmd/init.go[newCmdInit][SetDataInitializer]")
@@ -209,6 +273,18 @@ func newCmdInit(out io.Writer, initOptions *initOptions) *cobra.Command {
 		if len(initRunner.Options.SkipPhases) == 0 {
 			initRunner.Options.SkipPhases = data.cfg.SkipPhases
 		}
+		// 把--state-dir/--resume/--restart-from转发给Runner，启用各阶段执行状态的Journal持久化
+		initRunner.Options.StateDir = data.stateDir
+		initRunner.Options.Resume = data.resume
+		initRunner.Options.RestartFrom = data.restartFrom
+		if data.restart {
+			// --restart优先于--resume：放弃--state-dir下已有的Journal记录，把这一次执行当作
+			// 全新的开始，不跳过任何阶段，也不让之后的--resume误读到这一次被主动放弃的状态。
+			if err := initjournal.Discard(data.stateDir); err != nil {
+				return nil, err
+			}
+			initRunner.Options.Resume = false
+		}
 		return data, nil
 	})
 
@@ -299,6 +375,106 @@ func AddInitOtherFlags(flagSet *flag.FlagSet, initOptions *initOptions) {
 		"Don't print the key used to encrypt the control-plane certificates.",
 	)
 	options.AddPatchesFlag(flagSet, &initOptions.patchesDir)
+	flagSet.StringVarP(
+		&initOptions.joinOutputFormat, "output", "o", initOptions.joinOutputFormat,
+		"init成功后打印结果时使用的输出格式。可用的选项有: 'shell' (默认，打印join命令), 'json', 'yaml' "+
+			"(打印包含kubeConfigPath、join命令、各阶段执行状态等信息的结构化文档)。",
+	)
+	flagSet.IntVar(
+		&initOptions.imagePullParallelism, "image-pull-parallelism", initOptions.imagePullParallelism,
+		"在image-prepull阶段并发拉取容器镜像的最大数量。",
+	)
+	flagSet.IntVar(
+		&initOptions.imagePullRetries, "image-pull-retries", initOptions.imagePullRetries,
+		"单张镜像拉取失败后允许重试的最大次数。",
+	)
+	flagSet.DurationVar(
+		&initOptions.imagePullTimeout, "image-pull-timeout", initOptions.imagePullTimeout,
+		"image-prepull阶段预拉取全部所需镜像总共允许耗费的时长，超过此时长仍未完成会被视为失败。",
+	)
+	flagSet.BoolVar(
+		&initOptions.dryRunDiff, "dry-run-diff", initOptions.dryRunDiff,
+		"在试运行(--dry-run)下，对于节点上已经存在的文件，只打印新旧内容之间的差异，而不是完整的文件内容。",
+	)
+	flagSet.StringVar(
+		&initOptions.preflightPluginDir, "preflight-plugin-dir", initOptions.preflightPluginDir,
+		"扫描外部预检插件与声明式检查(可执行文件或.yaml文件)的目录。",
+	)
+	flagSet.StringVar(
+		&initOptions.waitEventsFile, "wait-events-file", initOptions.waitEventsFile,
+		"等待控制平面就绪期间，把每一次进度事件追加写入该文件(每行一个JSON对象)，供仪表盘或CI日志消费。",
+	)
+	flagSet.StringVar(
+		&initOptions.podNetworkAddon, "pod-network-addon", initOptions.podNetworkAddon,
+		fmt.Sprintf("从kubeadm内置目录中安装一个Pod网络插件(CNI)。可用的选项有: %s, 以及 %q (默认，不安装任何插件，"+
+			"保持此前需要手动apply一份Pod网络YAML的行为)。", strings.Join(cni.KnownAddons(), ", "), cni.None),
+	)
+	flagSet.StringVar(
+		&initOptions.podNetworkAddonConfig, "pod-network-addon-config", initOptions.podNetworkAddonConfig,
+		"使用一份自定义清单(本地文件路径或http(s)://URL)覆盖--pod-network-addon指定插件的内置清单。",
+	)
+	flagSet.StringVar(
+		&initOptions.cniConfDir, "cni-conf-dir", initOptions.cniConfDir,
+		"check-cni阶段用来查找Pod网络插件配置的目录，预期其中至少有一份可解析的*.conflist/*.conf文件。",
+	)
+	flagSet.StringVar(
+		&initOptions.cniBinDir, "cni-bin-dir", initOptions.cniBinDir,
+		"check-cni阶段用来查找--cni-conf-dir下配置所引用的各个CNI插件可执行文件的目录。",
+	)
+	flagSet.BoolVar(
+		&initOptions.strictCNI, "strict-cni", initOptions.strictCNI,
+		"check-cni阶段发现CNI配置或插件二进制缺失/不可用时，默认只打印警告；设置此项后改为直接失败。",
+	)
+	flagSet.StringVar(
+		&initOptions.stateDir, "state-dir", initOptions.stateDir,
+		"持久化各阶段执行状态(Journal)的目录，配合--resume/--restart-from实现中断后的续跑。",
+	)
+	flagSet.BoolVar(
+		&initOptions.resume, "resume", initOptions.resume,
+		"从--state-dir记录的Journal恢复执行：校验配置自上一次执行以来未发生变化后，跳过已经成功执行过的阶段。",
+	)
+	flagSet.StringVar(
+		&initOptions.restartFrom, "restart-from", initOptions.restartFrom,
+		"强制从指定阶段(完整路径名，与--skip-phases接受的值一致)开始重新执行，忽略该阶段及之后阶段在Journal中记录的状态。",
+	)
+	flagSet.BoolVar(
+		&initOptions.restart, "restart", initOptions.restart,
+		"放弃--state-dir下已有的Journal记录，把这一次执行当作全新的开始；与--resume同时指定时，--restart优先生效。",
+	)
+	flagSet.StringVar(
+		&initOptions.signerSocket, "signer-socket", initOptions.signerSocket,
+		"外部签名器插件监听的Unix域套接字路径。设置后，certs阶段在生成任何证书之前会先校验该插件是否可达，"+
+			"CA私钥由插件托管，不会落盘到本机(不能与--upload-certs同时使用)。",
+	)
+	flagSet.StringVar(
+		&initOptions.signerType, "signer-type", initOptions.signerType,
+		fmt.Sprintf("--signer-socket指向的外部签名器插件的后端种类，可用的取值有: %s, %s, %s。", signer.KMS, signer.PKCS11, signer.Vault),
+	)
+	flagSet.StringVar(
+		&initOptions.caKeyProviderURI, "ca-key-provider-uri", initOptions.caKeyProviderURI,
+		"用于托管CA私钥的keyprovider URI(参见cmd/kubeadm/app/phases/certs/keyprovider)，留空表示"+
+			"CA私钥与证书一样存放在--cert-dir指定的本地磁盘目录下。",
+	)
+	flagSet.BoolVar(
+		&initOptions.autoApproveKubeletServing, "auto-approve-kubelet-serving", initOptions.autoApproveKubeletServing,
+		"kubelet-server阶段提交kubelet serving证书的CSR后，使用管理员凭据自动批准该CSR，而不必等待外部审批。",
+	)
+	flagSet.BoolVar(
+		&initOptions.rotateKubeletServerCertOnExpiry, "rotate-kubelet-server-cert-on-expiry", initOptions.rotateKubeletServerCertOnExpiry,
+		"kubelet-server阶段签发的serving证书临近过期时，是否允许kubelet自行向certificates.k8s.io/v1申请续期(对应kubelet的serverTLSBootstrap)。",
+	)
+	flagSet.StringVar(
+		&initOptions.kubeConfigSigner, "kubeconfig-signer", initOptions.kubeConfigSigner,
+		"kubeconfig阶段签发admin/controller-manager/scheduler/kubelet客户端证书所使用的后端，取值为"+
+			"\"<backend>:<arg>\"，可用的backend有: csr(arg为一份具备批准CSR权限的已有kubeconfig路径)、"+
+			fmt.Sprintf("%s/%s/%s(arg为外部签名器插件的socket路径)。留空表示沿用本地CA签发证书。", signer.KMS, signer.PKCS11, signer.Vault),
+	)
+	flagSet.BoolVar(
+		&initOptions.testPKIFixtures, "test-pki-fixtures", initOptions.testPKIFixtures,
+		"使用一组预先生成的RSA密钥代替为kubeconfig/kubelet-server等阶段即时生成的密钥，仅供集成测试使用，"+
+			"以避免反复承受密钥生成的开销。",
+	)
+	_ = flagSet.MarkHidden("test-pki-fixtures")
 }
 
 // newInitOptions 返回可用于创建init命令标志的结构。
@@ -315,12 +491,21 @@ func newInitOptions() *initOptions {
 	bto.Description = "kubeadm init生成的默认引导令牌"
 
 	return &initOptions{
-		externalInitCfg:    externalInitCfg,
-		externalClusterCfg: externalClusterCfg,
-		bto:                bto,
-		kubeconfigDir:      kubeadmconstants.KubernetesDir,
-		kubeconfigPath:     kubeadmconstants.GetAdminKubeConfigPath(),
-		uploadCerts:        false,
+		externalInitCfg:      externalInitCfg,
+		externalClusterCfg:   externalClusterCfg,
+		bto:                  bto,
+		kubeconfigDir:        kubeadmconstants.KubernetesDir,
+		kubeconfigPath:       kubeadmconstants.GetAdminKubeConfigPath(),
+		uploadCerts:          false,
+		imagePullParallelism: preflight.DefaultPullOptions().Concurrency,
+		imagePullRetries:     preflight.DefaultPullOptions().MaxRetries,
+		imagePullTimeout:     preflight.DefaultPullOptions().Timeout,
+		preflightPluginDir:   preflight.DefaultPreflightPluginDir,
+		podNetworkAddon:      cni.None,
+		cniConfDir:           cniphase.DefaultConfDir,
+		cniBinDir:            cniphase.DefaultBinDir,
+		stateDir:             initjournal.DefaultStateDir,
+		signerType:           string(signer.KMS),
 	}
 }
 
@@ -328,6 +513,12 @@ func newInitOptions() *initOptions {
 // This func takes care of validating initOptions passed to the command, and then it converts
 // options into the internal InitConfiguration type that is used as input all the phases in the kubeadm init workflow
 func newInitData(cmd *cobra.Command, args []string, options *initOptions, out io.Writer) (*initData, error) {
+	// 集成测试可以通过隐藏标志--test-pki-fixtures触发和SetFixturePrivateKeys同样的效果，
+	// 而不必从Go代码里直接patch掉pkiutiltesting包的全局状态。
+	if options.testPKIFixtures {
+		pkiutiltesting.SetFixturePrivateKeys()
+	}
+
 	// Re-apply defaults to the public kubeadm API (this will set only values not exposed/not set as a flags)
 	kubeadmscheme.Scheme.Default(options.externalInitCfg)
 	kubeadmscheme.Scheme.Default(options.externalClusterCfg)
@@ -415,20 +606,46 @@ func newInitData(cmd *cobra.Command, args []string, options *initOptions, out io
 		return nil, errors.New("can't use upload-certs with an external CA or an external front-proxy CA")
 	}
 
+	if options.uploadCerts && options.signerSocket != "" {
+		return nil, errors.New("can't use upload-certs with an external signer (--signer-socket): the CA private key is held by the plugin and is never uploaded")
+	}
+
 	return &initData{
-		cfg:                     cfg,
-		certificatesDir:         cfg.CertificatesDir,
-		skipTokenPrint:          options.skipTokenPrint,
-		dryRun:                  options.dryRun,
-		dryRunDir:               dryRunDir,
-		kubeconfigDir:           options.kubeconfigDir,
-		kubeconfigPath:          options.kubeconfigPath,
-		ignorePreflightErrors:   ignorePreflightErrorsSet,
-		externalCA:              externalCA,
-		outputWriter:            out,
-		uploadCerts:             options.uploadCerts,
-		skipCertificateKeyPrint: options.skipCertificateKeyPrint,
-		patchesDir:              options.patchesDir,
+		cfg:                             cfg,
+		certificatesDir:                 cfg.CertificatesDir,
+		skipTokenPrint:                  options.skipTokenPrint,
+		dryRun:                          options.dryRun,
+		dryRunDir:                       dryRunDir,
+		kubeconfigDir:                   options.kubeconfigDir,
+		kubeconfigPath:                  options.kubeconfigPath,
+		ignorePreflightErrors:           ignorePreflightErrorsSet,
+		externalCA:                      externalCA,
+		outputWriter:                    out,
+		uploadCerts:                     options.uploadCerts,
+		skipCertificateKeyPrint:         options.skipCertificateKeyPrint,
+		patchesDir:                      options.patchesDir,
+		joinOutputFormat:                options.joinOutputFormat,
+		imagePullParallelism:            options.imagePullParallelism,
+		imagePullRetries:                options.imagePullRetries,
+		imagePullTimeout:                options.imagePullTimeout,
+		dryRunDiff:                      options.dryRunDiff,
+		preflightPluginDir:              options.preflightPluginDir,
+		waitEventsFile:                  options.waitEventsFile,
+		podNetworkAddon:                 options.podNetworkAddon,
+		podNetworkAddonConfig:           options.podNetworkAddonConfig,
+		cniConfDir:                      options.cniConfDir,
+		cniBinDir:                       options.cniBinDir,
+		strictCNI:                       options.strictCNI,
+		stateDir:                        options.stateDir,
+		resume:                          options.resume,
+		restartFrom:                     options.restartFrom,
+		restart:                         options.restart,
+		signerSocket:                    options.signerSocket,
+		signerType:                      options.signerType,
+		caKeyProviderURI:                options.caKeyProviderURI,
+		autoApproveKubeletServing:       options.autoApproveKubeletServing,
+		rotateKubeletServerCertOnExpiry: options.rotateKubeletServerCertOnExpiry,
+		kubeConfigSigner:                options.kubeConfigSigner,
 	}, nil
 }
 
@@ -472,6 +689,108 @@ func (d *initData) IgnorePreflightErrors() sets.String {
 	return d.ignorePreflightErrors
 }
 
+// ImagePullParallelism returns the number of image pull worker goroutines the image-prepull phase should use.
+func (d *initData) ImagePullParallelism() int {
+	return d.imagePullParallelism
+}
+
+// ImagePullRetries returns the maximum number of retries the image-prepull phase should perform per image.
+func (d *initData) ImagePullRetries() int {
+	return d.imagePullRetries
+}
+
+// ImagePullTimeout returns the overall timeout the image-prepull phase should allow for pulling all required images.
+func (d *initData) ImagePullTimeout() time.Duration {
+	return d.imagePullTimeout
+}
+
+// DryRunDiff returns the dryRunDiff flag.
+func (d *initData) DryRunDiff() bool {
+	return d.dryRunDiff
+}
+
+// PreflightPluginDir returns the directory that is scanned for external preflight check plugins.
+func (d *initData) PreflightPluginDir() string {
+	return d.preflightPluginDir
+}
+
+// WaitEventsFile returns the path events from the wait-control-plane phase should be appended to as JSON lines, or "" if disabled.
+func (d *initData) WaitEventsFile() string {
+	return d.waitEventsFile
+}
+
+// PodNetworkAddon returns the name of the Pod network addon the pod-network phase should install, or "none".
+func (d *initData) PodNetworkAddon() string {
+	return d.podNetworkAddon
+}
+
+// PodNetworkAddonConfig returns the file path or URL of a manifest overriding the built-in Pod network addon manifest.
+func (d *initData) PodNetworkAddonConfig() string {
+	return d.podNetworkAddonConfig
+}
+
+// CNIConfDir returns the directory the check-cni phase looks in for the on-disk CNI network configuration.
+func (d *initData) CNIConfDir() string {
+	return d.cniConfDir
+}
+
+// CNIBinDir returns the directory the check-cni phase looks in for the CNI plugin binaries referenced by CNIConfDir.
+func (d *initData) CNIBinDir() string {
+	return d.cniBinDir
+}
+
+// StrictCNI returns whether the check-cni phase should fail instead of warn when the CNI configuration or
+// one of its referenced plugin binaries is missing or unusable.
+func (d *initData) StrictCNI() bool {
+	return d.strictCNI
+}
+
+// ConfigFingerprint implements workflow.ConfigFingerprinter, returning a content hash of the effective
+// InitConfiguration so that workflow.Runner can verify, when --resume is set, that the config matches
+// the one recorded in the --state-dir Journal.
+func (d *initData) ConfigFingerprint() (string, error) {
+	return initjournal.HashConfig(d.cfg)
+}
+
+// SignerSocket returns the Unix domain socket path of the external signer plugin the certs phase should
+// delegate CSR signing to, or "" to keep signing locally with the on-disk CA.
+func (d *initData) SignerSocket() string {
+	return d.signerSocket
+}
+
+// SignerType returns the backend kind (kms, pkcs11 or vault) of the plugin at SignerSocket.
+func (d *initData) SignerType() string {
+	return d.signerType
+}
+
+// CAKeyProviderURI returns the --ca-key-provider-uri configured for this run, or "" to keep CA
+// private keys on local disk alongside their certificates (keyprovider.ParseURI defaults to the
+// file provider for an empty URI). This is threaded through initData/initOptions rather than
+// kubeadmapi.ClusterConfiguration because the real config API has no field for it; see
+// SignerSocket/SignerType above for the same pattern applied to the external-signer flags.
+func (d *initData) CAKeyProviderURI() string {
+	return d.caKeyProviderURI
+}
+
+// AutoApproveKubeletServing returns whether the kubelet-server phase should auto-approve the CSR it submits
+// for the kubelet's serving certificate, instead of waiting for an external approver.
+func (d *initData) AutoApproveKubeletServing() bool {
+	return d.autoApproveKubeletServing
+}
+
+// RotateOnExpiry returns whether the kubelet should be configured to request a new serving certificate from
+// certificates.k8s.io/v1 when the one issued by the kubelet-server phase is about to expire.
+func (d *initData) RotateOnExpiry() bool {
+	return d.rotateKubeletServerCertOnExpiry
+}
+
+// KubeConfigSigner returns the "<backend>:<arg>" spec of the KubeConfigSigner backend the kubeconfig
+// phase should use to mint admin/controller-manager/scheduler/kubelet client certificates, or an empty
+// string to keep signing them with the local CA as before.
+func (d *initData) KubeConfigSigner() string {
+	return d.kubeConfigSigner
+}
+
 // CertificateWriteDir returns the path to the certificate folder or the temporary folder path in case of DryRun.
 func (d *initData) CertificateWriteDir() string {
 	if d.dryRun {
@@ -527,6 +846,16 @@ func (d *initData) OutputWriter() io.Writer {
 	return d.outputWriter
 }
 
+// EventEmitter实现workflow.EventEmitterProvider。当--output被设置为json或yaml时，返回一个
+// 把每个阶段的PhaseEvent以NDJSON形式写入OutputWriter()的EventEmitter，与既有的面向人类的
+// 阶段提示并行输出，供自动化场景增量解析执行进度；--output为默认值(shell)时不发出结构化事件。
+func (d *initData) EventEmitter() workflow.EventEmitter {
+	if d.joinOutputFormat != "json" && d.joinOutputFormat != "yaml" {
+		return nil
+	}
+	return &workflow.NDJSONEventEmitter{Writer: d.outputWriter}
+}
+
 // Client returns a Kubernetes client to be used by kubeadm.
 // This function is implemented as a singleton, thus avoiding to recreate the client when it is used by different phases.
 // Important. This function must be called after the admin.conf kubeconfig file is created.
@@ -573,7 +902,19 @@ func (d *initData) PatchesDir() string {
 	return ""
 }
 
-func printJoinCommand(out io.Writer, adminKubeConfigPath, token string, i *initData) error {
+func printJoinCommand(out io.Writer, adminKubeConfigPath, token string, phaseStatuses []workflow.PhaseStatus, i *initData) error {
+	switch i.joinOutputFormat {
+	case "json", "yaml":
+		return printInitOutput(out, i.joinOutputFormat, adminKubeConfigPath, token, phaseStatuses, i)
+	case "", "shell":
+		return printJoinCommandShell(out, adminKubeConfigPath, token, i)
+	default:
+		return errors.Errorf("输出格式无效: %s", i.joinOutputFormat)
+	}
+}
+
+// printJoinCommandShell 以“kubeadm join”Shell命令的形式打印join信息，这是历史上唯一支持的输出格式。
+func printJoinCommandShell(out io.Writer, adminKubeConfigPath, token string, i *initData) error {
 	joinControlPlaneCommand, err := cmdutil.GetJoinControlPlaneCommand(adminKubeConfigPath, token, i.CertificateKey(), i.skipTokenPrint, i.skipCertificateKeyPrint)
 	if err != nil {
 		return err
@@ -595,13 +936,100 @@ func printJoinCommand(out io.Writer, adminKubeConfigPath, token string, i *initD
 	return initDoneTempl.Execute(out, ctx)
 }
 
+// printInitOutput 以outputapi.InitOutput描述的稳定schema打印结构化的JSON/YAML文档，
+// 取代此前只能通过正则解析面向人类的文本模板来提取kubeConfigPath/join命令等信息的做法。
+func printInitOutput(out io.Writer, format, adminKubeConfigPath, token string, phaseStatuses []workflow.PhaseStatus, i *initData) error {
+	workerCreds, err := cmdutil.GetJoinCredentials(adminKubeConfigPath, token, "", false)
+	if err != nil {
+		return err
+	}
+	redactJoinCredentials(workerCreds, i.skipTokenPrint, i.skipCertificateKeyPrint)
+
+	joinWorkerCommand, err := workerCreds.ShellCommand(i.skipTokenPrint, false)
+	if err != nil {
+		return err
+	}
+
+	var joinControlPlaneCommand string
+	certificateKey := i.CertificateKey()
+	if i.uploadCerts {
+		controlPlaneCreds, err := cmdutil.GetJoinCredentials(adminKubeConfigPath, token, certificateKey, true)
+		if err != nil {
+			return err
+		}
+		redactJoinCredentials(controlPlaneCreds, i.skipTokenPrint, i.skipCertificateKeyPrint)
+		joinControlPlaneCommand, err = controlPlaneCreds.ShellCommand(i.skipTokenPrint, i.skipCertificateKeyPrint)
+		if err != nil {
+			return err
+		}
+	} else {
+		certificateKey = ""
+	}
+
+	bootstrapToken := token
+	if i.skipTokenPrint {
+		bootstrapToken = "<value withheld>"
+	}
+	if i.skipCertificateKeyPrint && certificateKey != "" {
+		certificateKey = "<value withheld>"
+	}
+
+	doc := outputapi.InitOutput{
+		KubeConfigPath:          adminKubeConfigPath,
+		ControlPlaneEndpoint:    i.Cfg().ControlPlaneEndpoint,
+		CACertHashes:            workerCreds.CAPubKeyPins,
+		BootstrapToken:          bootstrapToken,
+		CertificateKey:          certificateKey,
+		JoinControlPlaneCommand: joinControlPlaneCommand,
+		JoinWorkerCommand:       joinWorkerCommand,
+		Phases:                  toOutputPhaseStatuses(phaseStatuses),
+	}
+
+	var data []byte
+	if format == "json" {
+		data, err = json.MarshalIndent(&doc, "", "  ")
+	} else {
+		data, err = yaml.Marshal(&doc)
+	}
+	if err != nil {
+		return errors.Wrap(err, "无法序列化init输出")
+	}
+
+	_, err = fmt.Fprintln(out, string(data))
+	return err
+}
+
+// toOutputPhaseStatuses把workflow.Runner计算出的阶段执行状态转换成outputapi的稳定schema。
+func toOutputPhaseStatuses(phaseStatuses []workflow.PhaseStatus) []outputapi.PhaseStatus {
+	result := make([]outputapi.PhaseStatus, 0, len(phaseStatuses))
+	for _, p := range phaseStatuses {
+		status := outputapi.PhaseStatusExecuted
+		if p.Skipped {
+			status = outputapi.PhaseStatusSkipped
+		}
+		result = append(result, outputapi.PhaseStatus{Name: p.Name, Status: status})
+	}
+	return result
+}
+
+// redactJoinCredentials 在--skip-token-print/--skip-certificate-key-print设置时，将对应的敏感字段
+// 替换为占位符，使结构化输出与Shell命令输出的隐藏行为保持一致。
+func redactJoinCredentials(creds *cmdutil.JoinCredentials, skipTokenPrint, skipCertificateKeyPrint bool) {
+	if skipTokenPrint {
+		creds.Token = "<value withheld>"
+	}
+	if skipCertificateKeyPrint && creds.CertificateKey != "" {
+		creds.CertificateKey = "<value withheld>"
+	}
+}
+
 // showJoinCommand 在init中的所有阶段完成后打印join命令
-func showJoinCommand(i *initData, out io.Writer) error {
+func showJoinCommand(i *initData, phaseStatuses []workflow.PhaseStatus, out io.Writer) error {
 	adminKubeConfigPath := i.KubeConfigPath()
 
 	// 如果用户有多个令牌，则多次打印join命令
 	for _, token := range i.Tokens() {
-		if err := printJoinCommand(out, adminKubeConfigPath, token, i); err != nil {
+		if err := printJoinCommand(out, adminKubeConfigPath, token, phaseStatuses, i); err != nil {
 			return errors.Wrap(err, "打印join命令失败")
 		}
 	}
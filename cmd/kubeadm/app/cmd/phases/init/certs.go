@@ -17,6 +17,7 @@ limitations under the License.
 package phases
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -30,7 +31,9 @@ import (
 	cmdutil "k8s.io/kubernetes/cmd/kubeadm/app/cmd/util"
 	kubeadmconstants "k8s.io/kubernetes/cmd/kubeadm/app/constants"
 	certsphase "k8s.io/kubernetes/cmd/kubeadm/app/phases/certs"
+	"k8s.io/kubernetes/cmd/kubeadm/app/phases/certs/keyprovider"
 	"k8s.io/kubernetes/cmd/kubeadm/app/util/pkiutil"
+	"k8s.io/kubernetes/cmd/kubeadm/app/util/signer"
 
 	"github.com/pkg/errors"
 )
@@ -74,6 +77,17 @@ func newCertSubPhases() []workflow.Phase {
 
 	subPhases = append(subPhases, allPhase)
 
+	// check-signer在写入任何证书文件之前，校验--signer-socket指向的外部签名器插件是否可达；
+	// 未设置--signer-socket时直接跳过。
+	checkSignerPhase := workflow.Phase{
+		Name:         "check-signer",
+		Short:        "校验外部签名器插件(--signer-socket)是否可达",
+		Run:          runCertsCheckSigner,
+		InheritFlags: []string{options.CfgPath, "signer-socket", "signer-type"},
+	}
+
+	subPhases = append(subPhases, checkSignerPhase)
+
 	// 此循环假设GetDefaultCertList()总是返回一个证书列表，该列表前面是对它们签名的CA。
 	var lastCACert *certsphase.KubeadmCert
 	for _, cert := range certsphase.GetDefaultCertList() {
@@ -101,7 +115,7 @@ func newCertSubPhases() []workflow.Phase {
 	return subPhases
 }
 
-func newCertSubPhase(certSpec *certsphase.KubeadmCert, run func(c workflow.RunData) error) workflow.Phase {
+func newCertSubPhase(certSpec *certsphase.KubeadmCert, run func(ctx context.Context, c workflow.RunData) error) workflow.Phase {
 	phase := workflow.Phase{
 		Name:  certSpec.Name,
 		Short: fmt.Sprintf("生成 %s", certSpec.LongName),
@@ -173,7 +187,7 @@ func getSANDescription(certSpec *certsphase.KubeadmCert) string {
 	return fmt.Sprintf("\n\nDefault SANs are %s", strings.Join(sans, ", "))
 }
 
-func runCertsSa(c workflow.RunData) error {
+func runCertsSa(_ context.Context, c workflow.RunData) error {
 	data, ok := c.(InitData)
 	if !ok {
 		return errors.New("使用无效的数据结构调用certs阶段")
@@ -189,7 +203,34 @@ func runCertsSa(c workflow.RunData) error {
 	return certsphase.CreateServiceAccountKeyAndPublicKeyFiles(data.CertificateWriteDir(), data.Cfg().ClusterConfiguration.PublicKeyAlgorithm())
 }
 
-func runCerts(c workflow.RunData) error {
+// runCertsCheckSigner在生成任何证书之前, 校验--signer-socket指向的外部签名器插件是否可达。
+// 未设置--signer-socket(留空, 即沿用本地CA签发证书)时直接跳过。
+func runCertsCheckSigner(_ context.Context, c workflow.RunData) error {
+	data, ok := c.(InitData)
+	if !ok {
+		return errors.New("使用无效的数据结构调用certs阶段")
+	}
+
+	socket := data.SignerSocket()
+	if socket == "" {
+		fmt.Printf("[证书] 未配置--signer-socket，使用本地CA签发证书\n")
+		return nil
+	}
+
+	s, err := signer.New(signer.Config{Type: signer.Type(data.SignerType()), Endpoint: socket})
+	if err != nil {
+		return errors.Wrap(err, "无法创建外部签名器客户端")
+	}
+
+	if err := s.Ping(); err != nil {
+		return errors.Wrapf(err, "外部签名器插件 %q 连通性检查失败", socket)
+	}
+
+	fmt.Printf("[证书] 外部签名器插件 %q 连通性检查通过\n", socket)
+	return nil
+}
+
+func runCerts(_ context.Context, c workflow.RunData) error {
 	data, ok := c.(InitData)
 	if !ok {
 		return errors.New("使用无效的数据结构调用certs阶段")
@@ -213,8 +254,8 @@ func runCerts(c workflow.RunData) error {
 	return nil
 }
 
-func runCAPhase(ca *certsphase.KubeadmCert) func(c workflow.RunData) error {
-	return func(c workflow.RunData) error {
+func runCAPhase(ca *certsphase.KubeadmCert) func(ctx context.Context, c workflow.RunData) error {
+	return func(_ context.Context, c workflow.RunData) error {
 		data, ok := c.(InitData)
 		if !ok {
 			return errors.New("使用无效的数据结构调用certs阶段")
@@ -233,6 +274,10 @@ func runCAPhase(ca *certsphase.KubeadmCert) func(c workflow.RunData) error {
 				fmt.Printf("[证书] 使用已经存在的 %s 认证授权\n", ca.BaseName)
 				return nil
 			}
+			if provider, err := keyprovider.ParseURI(data.CAKeyProviderURI()); err == nil && !provider.WritesKeyToDisk() {
+				fmt.Printf("[证书] %s 的私钥由 %q CA密钥Provider托管，不在本地磁盘\n", ca.BaseName, provider.Name())
+				return nil
+			}
 			fmt.Printf("[证书] 使用已经存在的 %s 无Key证书颁发机构\n", ca.BaseName)
 			return nil
 		}
@@ -247,8 +292,8 @@ func runCAPhase(ca *certsphase.KubeadmCert) func(c workflow.RunData) error {
 	}
 }
 
-func runCertPhase(cert *certsphase.KubeadmCert, caCert *certsphase.KubeadmCert) func(c workflow.RunData) error {
-	return func(c workflow.RunData) error {
+func runCertPhase(cert *certsphase.KubeadmCert, caCert *certsphase.KubeadmCert) func(ctx context.Context, c workflow.RunData) error {
+	return func(_ context.Context, c workflow.RunData) error {
 		data, ok := c.(InitData)
 		if !ok {
 			return errors.New("certs phase invoked with an invalid data struct")
@@ -274,6 +319,12 @@ func runCertPhase(cert *certsphase.KubeadmCert, caCert *certsphase.KubeadmCert)
 				return errors.Wrapf(err, "[certs] certificate %s not signed by CA certificate %s", cert.BaseName, caCert.BaseName)
 			}
 
+			if _, err := pkiutil.TryLoadKeyFromDisk(data.CertificateDir(), cert.BaseName); err != nil {
+				if provider, perr := keyprovider.ParseURI(data.CAKeyProviderURI()); perr == nil && !provider.WritesKeyToDisk() {
+					fmt.Printf("[certs] %s private key is managed by the %q CA key provider, not present on disk\n", cert.BaseName, provider.Name())
+				}
+			}
+
 			fmt.Printf("[certs] Using existing %s certificate and key on disk\n", cert.BaseName)
 			return nil
 		}
@@ -0,0 +1,82 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/kubernetes/cmd/kubeadm/app/cmd/options"
+	"k8s.io/kubernetes/cmd/kubeadm/app/cmd/phases/workflow"
+	cniphase "k8s.io/kubernetes/cmd/kubeadm/app/phases/cni"
+
+	"github.com/pkg/errors"
+)
+
+// NewCheckCNIPhase 是一个隐藏阶段，在kubelet-start阶段之后运行，检查--cni-conf-dir下是否存在
+// 可用的网络配置、其中引用的插件二进制是否都能在--cni-bin-dir下找到并正确握手——kubelet在这两者
+// 缺失时只会把节点卡在NotReady，而不会报出一个指向CNI配置本身的错误，这个阶段把check-etcd对
+// etcd健康状态的检查思路对应到了Pod网络插件上。
+func NewCheckCNIPhase() workflow.Phase {
+	return workflow.Phase{
+		Name:   "check-cni",
+		Run:    runCheckCNIPhase,
+		Hidden: true,
+		InheritFlags: []string{
+			options.CNIConfDir,
+			options.CNIBinDir,
+			options.StrictCNI,
+		},
+	}
+}
+
+func runCheckCNIPhase(_ context.Context, c workflow.RunData) error {
+	data, ok := c.(InitData)
+	if !ok {
+		return errors.New("check-cni阶段使用无效数据结构调用")
+	}
+
+	addonName := data.PodNetworkAddon()
+	if addonName == "" {
+		fmt.Println("[check-cni] 未指定 --pod-network-addon，跳过CNI配置检查")
+		return nil
+	}
+
+	status, err := cniphase.ValidateSetup(data.CNIConfDir(), data.CNIBinDir())
+	if err != nil {
+		if data.StrictCNI() {
+			return err
+		}
+		fmt.Printf("[check-cni] WARNING: %v\n", err)
+		return nil
+	}
+
+	if len(status.Problems) == 0 {
+		fmt.Printf("[check-cni] %s看起来工作正常\n", status.ConfigFile)
+		return nil
+	}
+
+	for _, problem := range status.Problems {
+		fmt.Printf("[check-cni] WARNING: 插件 %q: %s\n", problem.Type, problem.Reason)
+	}
+	fmt.Println("[check-cni] 以上插件缺失或不可用时，kubelet可能会把节点长期停留在NotReady状态")
+
+	if data.StrictCNI() {
+		return errors.Errorf("%s引用的 %d 个CNI插件未通过检查", status.ConfigFile, len(status.Problems))
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/kubernetes/cmd/kubeadm/app/cmd/options"
+	"k8s.io/kubernetes/cmd/kubeadm/app/cmd/phases/workflow"
+	cmdutil "k8s.io/kubernetes/cmd/kubeadm/app/cmd/util"
+	"k8s.io/kubernetes/cmd/kubeadm/app/preflight"
+	utilruntime "k8s.io/kubernetes/cmd/kubeadm/app/util/runtime"
+
+	utilsexec "k8s.io/utils/exec"
+
+	"github.com/pkg/errors"
+)
+
+var imagePrepullExample = cmdutil.Examples(`
+	# Pre-pull the images required to run the control plane before the kubelet is asked to start it.
+	kubeadm init phase image-prepull --config kubeadm-config.yaml
+	`)
+
+// NewImagePrepullPhase 创建kubeadm工作流阶段，在wait-control-plane之前通过CRI套接字直接预拉取控制平面与
+// （若使用本地etcd）etcd所需的全部镜像。这样一来，镜像拉取失败会作为一次带有具体镜像名、镜像仓库地址与
+// CRI错误详情的明确失败直接报出，而不会像此前那样被wait-control-plane笼统地归结为"kubelet未就绪"，
+// 让使用者误以为是kubelet配置问题。
+func NewImagePrepullPhase() workflow.Phase {
+	return workflow.Phase{
+		Name:    "image-prepull",
+		Short:   "预拉取控制平面所需的容器镜像",
+		Long:    "在启动kubelet与控制平面静态Pod之前，通过CRI套接字直接预拉取所需的全部容器镜像",
+		Example: imagePrepullExample,
+		Run:     runImagePrepull,
+		InheritFlags: []string{
+			options.CfgPath,
+			"image-pull-parallelism",
+			"image-pull-retries",
+			"image-pull-timeout",
+		},
+	}
+}
+
+// runImagePrepull 执行预拉取逻辑
+func runImagePrepull(_ context.Context, c workflow.RunData) error {
+	data, ok := c.(InitData)
+	if !ok {
+		return errors.New("使用无效数据结构调用image-prepull阶段")
+	}
+
+	cfg := data.Cfg()
+	images := preflight.RequiredImages(&cfg.ClusterConfiguration)
+
+	if data.DryRun() {
+		fmt.Fprintf(data.OutputWriter(), "[image-prepull] 试运行: 将会预拉取以下 %d 张镜像 (已跳过实际拉取):\n", len(images))
+		for _, image := range images {
+			fmt.Fprintf(data.OutputWriter(), "[image-prepull] \t%s\n", image)
+		}
+		return nil
+	}
+
+	criSocket := cfg.NodeRegistration.CRISocket
+	runtime, err := utilruntime.NewContainerRuntime(utilsexec.New(), criSocket)
+	if err != nil {
+		return errors.Wrapf(err, "无法通过CRI套接字 %q 连接容器运行时", criSocket)
+	}
+
+	fmt.Fprintf(data.OutputWriter(), "[image-prepull] 通过 %q 预拉取 %d 张镜像\n", criSocket, len(images))
+
+	opts := preflight.DefaultPullOptions()
+	opts.ProgressWriter = data.OutputWriter()
+	if data.ImagePullParallelism() > 0 {
+		opts.Concurrency = data.ImagePullParallelism()
+	}
+	if data.ImagePullRetries() > 0 {
+		opts.MaxRetries = data.ImagePullRetries()
+	}
+	if data.ImagePullTimeout() > 0 {
+		opts.Timeout = data.ImagePullTimeout()
+	}
+
+	if err := preflight.RunPullImagesCheckWithOptions(runtime, images, opts); err != nil {
+		return errors.Wrap(err, "预拉取镜像失败")
+	}
+	return nil
+}
@@ -17,19 +17,43 @@ limitations under the License.
 package phases
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 
+	clientcmd "k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	kubeadmapi "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm"
 	"k8s.io/kubernetes/cmd/kubeadm/app/cmd/options"
 	"k8s.io/kubernetes/cmd/kubeadm/app/cmd/phases/workflow"
 	cmdutil "k8s.io/kubernetes/cmd/kubeadm/app/cmd/util"
 	kubeadmconstants "k8s.io/kubernetes/cmd/kubeadm/app/constants"
 	kubeconfigphase "k8s.io/kubernetes/cmd/kubeadm/app/phases/kubeconfig"
+	pkiutiltesting "k8s.io/kubernetes/cmd/kubeadm/app/util/pkiutil/testing"
 
 	"github.com/pkg/errors"
 )
 
+// kubeConfigFileIdentity描述一份kubeconfig客户端证书应当携带的身份, 供KubeConfigSigner后端
+// 签发证书时使用。
+var kubeConfigFileIdentity = map[string]struct {
+	commonName   string
+	organization string
+}{
+	kubeadmconstants.AdminKubeConfigFileName:             {commonName: "kubernetes-admin", organization: "system:masters"},
+	kubeadmconstants.ControllerManagerKubeConfigFileName: {commonName: "system:kube-controller-manager"},
+	kubeadmconstants.SchedulerKubeConfigFileName:         {commonName: "system:kube-scheduler"},
+}
+
 var (
 	kubeconfigFilePhaseProperties = map[string]struct {
 		name  string
@@ -79,6 +103,7 @@ func NewKubeConfigPhase() workflow.Phase {
 			NewKubeConfigFilePhase(kubeadmconstants.KubeletKubeConfigFileName),
 			NewKubeConfigFilePhase(kubeadmconstants.ControllerManagerKubeConfigFileName),
 			NewKubeConfigFilePhase(kubeadmconstants.SchedulerKubeConfigFileName),
+			NewKubeletServerCertPhase(),
 		},
 		Run: runKubeConfig,
 	}
@@ -104,6 +129,7 @@ func getKubeConfigPhaseFlags(name string) []string {
 		options.CfgPath,
 		options.KubeconfigDir,
 		options.KubernetesVersion,
+		"kubeconfig-signer",
 	}
 	if name == "all" || name == kubeadmconstants.KubeletKubeConfigFileName {
 		flags = append(flags,
@@ -113,7 +139,7 @@ func getKubeConfigPhaseFlags(name string) []string {
 	return flags
 }
 
-func runKubeConfig(c workflow.RunData) error {
+func runKubeConfig(_ context.Context, c workflow.RunData) error {
 	data, ok := c.(InitData)
 	if !ok {
 		return errors.New("使用无效的数据结构调用kubeconfig阶段")
@@ -124,8 +150,8 @@ func runKubeConfig(c workflow.RunData) error {
 }
 
 // runKubeConfigFile executes kubeconfig creation logic.
-func runKubeConfigFile(kubeConfigFileName string) func(workflow.RunData) error {
-	return func(c workflow.RunData) error {
+func runKubeConfigFile(kubeConfigFileName string) func(ctx context.Context, c workflow.RunData) error {
+	return func(_ context.Context, c workflow.RunData) error {
 		data, ok := c.(InitData)
 		if !ok {
 			return errors.New("kubeconfig phase invoked with an invalid data struct")
@@ -150,6 +176,12 @@ func runKubeConfigFile(kubeConfigFileName string) func(workflow.RunData) error {
 			return nil
 		}
 
+		// if a KubeConfigSigner backend is configured, mint the kubeconfig via that backend instead of
+		// the local CA, so that the CA private key never has to be read off disk for this file.
+		if data.KubeConfigSigner() != "" {
+			return runKubeConfigFileWithSigner(kubeConfigFileName, data)
+		}
+
 		// if dryrunning, reads certificates from a temporary folder (and defer restore to the path originally specified by the user)
 		cfg := data.Cfg()
 		cfg.CertificatesDir = data.CertificateWriteDir()
@@ -159,3 +191,81 @@ func runKubeConfigFile(kubeConfigFileName string) func(workflow.RunData) error {
 		return kubeconfigphase.CreateKubeConfigFile(kubeConfigFileName, data.KubeConfigDir(), data.Cfg())
 	}
 }
+
+// runKubeConfigFileWithSigner通过data.KubeConfigSigner()指定的后端签发kubeConfigFileName所需的
+// 客户端证书, 并写出对应的kubeconfig文件, 而不调用kubeconfigphase.CreateKubeConfigFile(后者总是
+// 使用本地持有的CA私钥)。
+func runKubeConfigFileWithSigner(kubeConfigFileName string, data InitData) error {
+	identity, ok := kubeConfigFileIdentity[kubeConfigFileName]
+	if !ok && kubeConfigFileName != kubeadmconstants.KubeletKubeConfigFileName {
+		return errors.Errorf("kubeconfig-signer不支持为%s签发证书", kubeConfigFileName)
+	}
+	if kubeConfigFileName == kubeadmconstants.KubeletKubeConfigFileName {
+		identity.commonName = fmt.Sprintf("system:node:%s", data.Cfg().NodeRegistration.Name)
+		identity.organization = "system:nodes"
+	}
+
+	kubeConfigSigner, err := newKubeConfigSigner(data.KubeConfigSigner(), data.CertificateDir())
+	if err != nil {
+		return err
+	}
+
+	key, err := pkiutiltesting.NewPrivateKey()
+	if err != nil {
+		return errors.Wrapf(err, "无法为%s生成密钥", kubeConfigFileName)
+	}
+
+	subject := pkix.Name{CommonName: identity.commonName}
+	if identity.organization != "" {
+		subject.Organization = []string{identity.organization}
+	}
+
+	csrDER, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{Subject: subject}, key)
+	if err != nil {
+		return errors.Wrapf(err, "无法为%s生成CSR", kubeConfigFileName)
+	}
+	csr, err := x509.ParseCertificateRequest(csrDER)
+	if err != nil {
+		return errors.Wrapf(err, "无法解析%s的CSR", kubeConfigFileName)
+	}
+
+	certPEM, err := kubeConfigSigner.SignClientCert(csr)
+	if err != nil {
+		return errors.Wrapf(err, "无法为%s签发证书", kubeConfigFileName)
+	}
+
+	caData, err := kubeConfigSigner.CAData()
+	if err != nil {
+		return err
+	}
+
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	contextName := strings.TrimSuffix(kubeConfigFileName, filepath.Ext(kubeConfigFileName))
+	kubeconfig := buildSignedKubeConfig(data.Cfg(), contextName, caData, certPEM, keyPEM)
+	kubeconfigPath := filepath.Join(data.KubeConfigDir(), kubeConfigFileName)
+	if err := clientcmd.WriteToFile(*kubeconfig, kubeconfigPath); err != nil {
+		return errors.Wrapf(err, "无法写入%s", kubeConfigFileName)
+	}
+
+	fmt.Printf("[kubeconfig] 已通过--kubeconfig-signer签发证书并写入 %s\n", kubeconfigPath)
+	return nil
+}
+
+// buildSignedKubeConfig构建一份引用已签发客户端证书(以内嵌数据而非文件路径的形式)的kubeconfig。
+func buildSignedKubeConfig(cfg *kubeadmapi.InitConfiguration, contextName string, caData, certPEM, keyPEM []byte) *clientcmdapi.Config {
+	serverURL := fmt.Sprintf("https://%s", net.JoinHostPort(cfg.LocalAPIEndpoint.AdvertiseAddress, strconv.Itoa(int(cfg.LocalAPIEndpoint.BindPort))))
+
+	return &clientcmdapi.Config{
+		Clusters: map[string]*clientcmdapi.Cluster{
+			contextName: {Server: serverURL, CertificateAuthorityData: caData},
+		},
+		Contexts: map[string]*clientcmdapi.Context{
+			contextName: {Cluster: contextName, AuthInfo: contextName},
+		},
+		AuthInfos: map[string]*clientcmdapi.AuthInfo{
+			contextName: {ClientCertificateData: certPEM, ClientKeyData: keyPEM},
+		},
+		CurrentContext: contextName,
+	}
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"os"
+	"testing"
+
+	kubeadmconstants "k8s.io/kubernetes/cmd/kubeadm/app/constants"
+	pkiutiltesting "k8s.io/kubernetes/cmd/kubeadm/app/util/pkiutil/testing"
+)
+
+// TestMain让本包内所有测试都复用一组预先生成的RSA密钥, 而不必为每一次调用
+// runKubeConfigFileWithSigner/runKubeletServerCert都重新生成2048位密钥。
+func TestMain(m *testing.M) {
+	pkiutiltesting.SetFixturePrivateKeys()
+	os.Exit(m.Run())
+}
+
+func TestKubeConfigFileIdentity(t *testing.T) {
+	adminIdentity, ok := kubeConfigFileIdentity[kubeadmconstants.AdminKubeConfigFileName]
+	if !ok {
+		t.Fatalf("kubeConfigFileIdentity缺少%s的条目", kubeadmconstants.AdminKubeConfigFileName)
+	}
+	if adminIdentity.commonName != "kubernetes-admin" {
+		t.Errorf("%s的commonName = %q, 期望为 %q", kubeadmconstants.AdminKubeConfigFileName, adminIdentity.commonName, "kubernetes-admin")
+	}
+	if adminIdentity.organization != "system:masters" {
+		t.Errorf("%s的organization = %q, 期望为 %q", kubeadmconstants.AdminKubeConfigFileName, adminIdentity.organization, "system:masters")
+	}
+}
+
+func TestNewPrivateKeyUsesFixtures(t *testing.T) {
+	first, err := pkiutiltesting.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("NewPrivateKey返回了意料之外的错误: %v", err)
+	}
+	second, err := pkiutiltesting.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("NewPrivateKey返回了意料之外的错误: %v", err)
+	}
+	if first == nil || second == nil {
+		t.Fatalf("NewPrivateKey不应该返回nil")
+	}
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	kubeadmconstants "k8s.io/kubernetes/cmd/kubeadm/app/constants"
+	"k8s.io/kubernetes/cmd/kubeadm/app/util/signer"
+
+	"github.com/pkg/errors"
+)
+
+// kubeConfigClientSignerName是kubeConfigSigner后端"csr"提交CSR时使用的签名者, 专门用于签发
+// 面向kube-apiserver的客户端认证证书(admin/controller-manager/scheduler等kubeconfig所需)。
+const kubeConfigClientSignerName = "kubernetes.io/kube-apiserver-client"
+
+// kubeConfigCSRWaitTimeout是等待kubeConfigSigner后端"csr"提交的CSR被签发允许耗费的最长时长。
+const kubeConfigCSRWaitTimeout = 1 * time.Minute
+
+// KubeConfigSigner把kubeconfig阶段需要的客户端证书签发能力抽象成一个接口, 使runKubeConfigFile
+// 不必关心证书究竟是由哪种后端签发的(本地CA、通过一份已有kubeconfig向certificates.k8s.io/v1
+// 提交CSR、还是交给一个PKCS#11/KMS/Vault外部签名器插件)。
+type KubeConfigSigner interface {
+	// SignClientCert对csr签名, 返回PEM编码的客户端证书。
+	SignClientCert(csr *x509.CertificateRequest) ([]byte, error)
+	// CAData返回应当写入kubeconfig的cluster-ca-data字段的PEM编码CA证书。
+	CAData() ([]byte, error)
+}
+
+// newKubeConfigSigner依据--kubeconfig-signer的取值创建对应后端的KubeConfigSigner。spec的格式为
+// "<backend>:<arg>"，已知的backend为csr(arg是一份已有kubeconfig的路径)以及pkcs11/kms/vault
+// (arg是外部签名器插件的socket路径，与--signer-socket使用同一套协议)。
+func newKubeConfigSigner(spec string, certificatesDir string) (KubeConfigSigner, error) {
+	backend, arg, ok := strings.Cut(spec, ":")
+	if !ok || arg == "" {
+		return nil, errors.Errorf("--kubeconfig-signer的取值无效, 期望格式为\"<backend>:<arg>\", 实际取值为 %q", spec)
+	}
+
+	switch backend {
+	case "csr":
+		client, err := getClientsetFromKubeConfig(arg)
+		if err != nil {
+			return nil, errors.Wrapf(err, "无法从 %q 构建用于提交CSR的客户端", arg)
+		}
+		return &csrKubeConfigSigner{client: client, certificatesDir: certificatesDir}, nil
+	case string(signer.KMS), string(signer.PKCS11), string(signer.Vault):
+		s, err := signer.New(signer.Config{Type: signer.Type(backend), Endpoint: arg})
+		if err != nil {
+			return nil, errors.Wrap(err, "无法创建外部签名器客户端")
+		}
+		return &pluginKubeConfigSigner{signer: s, certificatesDir: certificatesDir}, nil
+	default:
+		return nil, errors.Errorf("未知的--kubeconfig-signer后端 %q，可用的取值有: csr, %s, %s, %s",
+			backend, signer.KMS, signer.PKCS11, signer.Vault)
+	}
+}
+
+// csrKubeConfigSigner是KubeConfigSigner的一个实现: 通过一份已有的kubeconfig(通常是一份具备
+// 批准CSR权限的管理员凭据)连接集群, 向certificates.k8s.io/v1提交CSR并自行批准, 而不是依赖
+// 本地持有的CA私钥签发证书。
+type csrKubeConfigSigner struct {
+	client          clientset.Interface
+	certificatesDir string
+}
+
+// SignClientCert实现KubeConfigSigner。
+func (s *csrKubeConfigSigner) SignClientCert(csr *x509.CertificateRequest) ([]byte, error) {
+	csrPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csr.Raw})
+
+	csrObj := &certificatesv1.CertificateSigningRequest{
+		ObjectMeta: metav1.ObjectMeta{GenerateName: fmt.Sprintf("%s-", strings.ToLower(csr.Subject.CommonName))},
+		Spec: certificatesv1.CertificateSigningRequestSpec{
+			Request:    csrPEM,
+			SignerName: kubeConfigClientSignerName,
+			Usages: []certificatesv1.KeyUsage{
+				certificatesv1.UsageDigitalSignature,
+				certificatesv1.UsageKeyEncipherment,
+				certificatesv1.UsageClientAuth,
+			},
+		},
+	}
+
+	created, err := s.client.CertificatesV1().CertificateSigningRequests().Create(context.TODO(), csrObj, metav1.CreateOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "无法提交CSR")
+	}
+
+	created.Status.Conditions = append(created.Status.Conditions, certificatesv1.CertificateSigningRequestCondition{
+		Type:    certificatesv1.CertificateApproved,
+		Status:  "True",
+		Reason:  "KubeadmAutoApprove",
+		Message: "kubeadm在kubeconfig阶段自动批准了此CSR",
+	})
+	if _, err := s.client.CertificatesV1().CertificateSigningRequests().UpdateApproval(context.TODO(), created.Name, created, metav1.UpdateOptions{}); err != nil {
+		return nil, errors.Wrapf(err, "无法自动批准CSR %q", created.Name)
+	}
+
+	return s.waitForCert(created.Name)
+}
+
+func (s *csrKubeConfigSigner) waitForCert(csrName string) ([]byte, error) {
+	var certPEM []byte
+	err := wait.PollImmediate(2*time.Second, kubeConfigCSRWaitTimeout, func() (bool, error) {
+		csr, err := s.client.CertificatesV1().CertificateSigningRequests().Get(context.TODO(), csrName, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		for _, cond := range csr.Status.Conditions {
+			if cond.Type == certificatesv1.CertificateDenied || cond.Type == certificatesv1.CertificateFailed {
+				return false, errors.Errorf("CSR %q 未能签发: %s", csrName, cond.Message)
+			}
+		}
+		if len(csr.Status.Certificate) == 0 {
+			return false, nil
+		}
+		certPEM = csr.Status.Certificate
+		return true, nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "等待CSR %q 签发证书超时", csrName)
+	}
+	return certPEM, nil
+}
+
+// CAData实现KubeConfigSigner。即便证书由集群的CSR API签发, 验证kube-apiserver身份仍然需要
+// 本地持有的CA证书, 因此与file后端一样从certificatesDir读取。
+func (s *csrKubeConfigSigner) CAData() ([]byte, error) {
+	return readLocalCAData(s.certificatesDir)
+}
+
+// pluginKubeConfigSigner是KubeConfigSigner的一个实现: 把CSR签名操作委托给--kubeconfig-signer
+// 指向的外部签名器插件(KMS、PKCS#11 HSM或Vault), 本地永远不持有CA私钥。
+type pluginKubeConfigSigner struct {
+	signer          signer.Signer
+	certificatesDir string
+}
+
+// SignClientCert实现KubeConfigSigner。
+func (s *pluginKubeConfigSigner) SignClientCert(csr *x509.CertificateRequest) ([]byte, error) {
+	csrPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csr.Raw})
+	certPEM, err := s.signer.Sign(csrPEM)
+	if err != nil {
+		return nil, errors.Wrap(err, "外部签名器插件拒绝签发客户端证书")
+	}
+	return certPEM, nil
+}
+
+// CAData实现KubeConfigSigner。外部签名器插件只负责签名，CA证书仍然从本地certificatesDir读取，
+// 与certs阶段的--signer-socket约定一致。
+func (s *pluginKubeConfigSigner) CAData() ([]byte, error) {
+	return readLocalCAData(s.certificatesDir)
+}
+
+// readLocalCAData从certificatesDir读取PEM编码的CA证书, 供KubeConfigSigner的实现嵌入kubeconfig。
+func readLocalCAData(certificatesDir string) ([]byte, error) {
+	caCert, err := os.ReadFile(filepath.Join(certificatesDir, kubeadmconstants.CACertName))
+	if err != nil {
+		return nil, errors.Wrap(err, "无法读取CA证书")
+	}
+	return caCert, nil
+}
+
+// getClientsetFromKubeConfig从kubeconfigPath指向的文件构建一个clientset, 供csrKubeConfigSigner
+// 用来提交和批准CSR。
+func getClientsetFromKubeConfig(kubeconfigPath string) (clientset.Interface, error) {
+	config, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+	if err != nil {
+		return nil, err
+	}
+	return clientset.NewForConfig(config)
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package phases
 
 import (
+	"context"
 	"fmt"
 
 	"k8s.io/kubernetes/cmd/kubeadm/app/cmd/options"
@@ -53,7 +54,7 @@ func NewKubeletStartPhase() workflow.Phase {
 }
 
 // runKubeletStart 执行kubelet启动逻辑
-func runKubeletStart(c workflow.RunData) error {
+func runKubeletStart(_ context.Context, c workflow.RunData) error {
 	data, ok := c.(InitData)
 	if !ok {
 		return errors.New("启动kubelet阶段使用无效数据结构")
@@ -81,7 +82,9 @@ func runKubeletStart(c workflow.RunData) error {
 	// Try to start the kubelet service in case it's inactive
 	if !data.DryRun() {
 		fmt.Println("[启动kubelet] 正在启动kubelet")
-		kubeletphase.TryStartKubelet()
+		if err := kubeletphase.TryStartKubelet(); err != nil {
+			return errors.Wrap(err, "启动kubelet失败")
+		}
 	}
 
 	return nil
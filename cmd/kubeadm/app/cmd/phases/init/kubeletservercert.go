@@ -0,0 +1,245 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientset "k8s.io/client-go/kubernetes"
+	clientcmd "k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	kubeadmapi "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm"
+	"k8s.io/kubernetes/cmd/kubeadm/app/cmd/options"
+	"k8s.io/kubernetes/cmd/kubeadm/app/cmd/phases/workflow"
+	kubeadmconstants "k8s.io/kubernetes/cmd/kubeadm/app/constants"
+	pkiutiltesting "k8s.io/kubernetes/cmd/kubeadm/app/util/pkiutil/testing"
+
+	"github.com/pkg/errors"
+)
+
+// kubeletServingSignerName是kubelet-server阶段提交CSR时使用的签名者, 专门用于签发kubelet serving证书。
+const kubeletServingSignerName = "kubernetes.io/kubelet-serving"
+
+// kubeletServerKubeConfigFileName是kubelet-server阶段写出的kubeconfig文件名。
+const kubeletServerKubeConfigFileName = "kubelet-server.conf"
+
+// kubeletServerCertWaitTimeout是等待CSR被批准并签发证书允许耗费的最长时长。
+const kubeletServerCertWaitTimeout = 1 * time.Minute
+
+// NewKubeletServerCertPhase创建一个kubeadm工作流阶段, 作为kubeconfig阶段的兄弟阶段: 它通过向
+// certificates.k8s.io/v1提交一个kubernetes.io/kubelet-serving类型的CSR获取kubelet的serving证书,
+// 并生成一份引用该证书的kubelet-server.conf, 而不是像其余kubeconfig文件那样直接用本地CA签发。
+func NewKubeletServerCertPhase() workflow.Phase {
+	return workflow.Phase{
+		Name:  "kubelet-server",
+		Short: "生成带有kubelet serving证书的kubeconfig文件",
+		Long:  "向certificates.k8s.io/v1提交kubernetes.io/kubelet-serving类型的CSR以获取kubelet serving证书, 并生成引用该证书的kubeconfig文件。",
+		Run:   runKubeletServerCert,
+		InheritFlags: []string{
+			options.APIServerAdvertiseAddress,
+			options.CertificatesDir,
+			options.CfgPath,
+			options.KubeconfigDir,
+			options.NodeName,
+			"auto-approve-kubelet-serving",
+		},
+	}
+}
+
+func runKubeletServerCert(_ context.Context, c workflow.RunData) error {
+	data, ok := c.(InitData)
+	if !ok {
+		return errors.New("使用无效的数据结构调用kubelet-server阶段")
+	}
+
+	if data.ExternalCA() {
+		fmt.Println("[kubelet-server] 外部CA模式: 跳过kubelet serving证书的CSR申请")
+		return nil
+	}
+
+	client, err := data.Client()
+	if err != nil {
+		return errors.Wrap(err, "无法获取用于提交CSR的客户端")
+	}
+
+	key, err := pkiutiltesting.NewPrivateKey()
+	if err != nil {
+		return errors.Wrap(err, "无法为kubelet serving证书生成密钥")
+	}
+
+	nodeName := data.Cfg().NodeRegistration.Name
+	advertiseAddress := data.Cfg().LocalAPIEndpoint.AdvertiseAddress
+
+	csrTemplate := &x509.CertificateRequest{
+		Subject: pkix.Name{
+			CommonName:   fmt.Sprintf("system:node:%s", nodeName),
+			Organization: []string{"system:nodes"},
+		},
+		DNSNames:    []string{nodeName},
+		IPAddresses: parseIP(advertiseAddress),
+	}
+
+	csrDER, err := x509.CreateCertificateRequest(rand.Reader, csrTemplate, key)
+	if err != nil {
+		return errors.Wrap(err, "无法生成kubelet serving证书的CSR")
+	}
+	csrPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrDER})
+
+	csrObj := &certificatesv1.CertificateSigningRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: fmt.Sprintf("%s-kubelet-serving-", nodeName),
+		},
+		Spec: certificatesv1.CertificateSigningRequestSpec{
+			Request:    csrPEM,
+			SignerName: kubeletServingSignerName,
+			Usages: []certificatesv1.KeyUsage{
+				certificatesv1.UsageDigitalSignature,
+				certificatesv1.UsageKeyEncipherment,
+				certificatesv1.UsageServerAuth,
+			},
+		},
+	}
+
+	created, err := client.CertificatesV1().CertificateSigningRequests().Create(context.TODO(), csrObj, metav1.CreateOptions{})
+	if err != nil {
+		return errors.Wrap(err, "无法提交kubelet serving证书的CSR")
+	}
+
+	if data.AutoApproveKubeletServing() {
+		created.Status.Conditions = append(created.Status.Conditions, certificatesv1.CertificateSigningRequestCondition{
+			Type:    certificatesv1.CertificateApproved,
+			Status:  "True",
+			Reason:  "KubeadmAutoApprove",
+			Message: "kubeadm在kubelet-server阶段自动批准了此CSR",
+		})
+		if _, err := client.CertificatesV1().CertificateSigningRequests().UpdateApproval(context.TODO(), created.Name, created, metav1.UpdateOptions{}); err != nil {
+			return errors.Wrapf(err, "无法自动批准CSR %q", created.Name)
+		}
+		fmt.Printf("[kubelet-server] 已自动批准CSR %q\n", created.Name)
+	} else {
+		fmt.Printf("[kubelet-server] 已提交CSR %q, 等待外部审批\n", created.Name)
+	}
+
+	certPEM, err := waitForKubeletServerCert(client, created.Name)
+	if err != nil {
+		return err
+	}
+
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	certPath := filepath.Join(data.CertificateWriteDir(), "kubelet-server.crt")
+	keyPath := filepath.Join(data.CertificateWriteDir(), "kubelet-server.key")
+	if err := os.WriteFile(certPath, certPEM, 0600); err != nil {
+		return errors.Wrap(err, "无法写入kubelet serving证书")
+	}
+	if err := os.WriteFile(keyPath, keyPEM, 0600); err != nil {
+		return errors.Wrap(err, "无法写入kubelet serving证书私钥")
+	}
+
+	kubeconfig, err := buildKubeletServerKubeConfig(data.Cfg(), certPEM, keyPEM)
+	if err != nil {
+		return err
+	}
+	kubeconfigPath := filepath.Join(data.KubeConfigDir(), kubeletServerKubeConfigFileName)
+	if err := clientcmd.WriteToFile(*kubeconfig, kubeconfigPath); err != nil {
+		return errors.Wrapf(err, "无法写入%s", kubeletServerKubeConfigFileName)
+	}
+
+	fmt.Printf("[kubelet-server] 已写入 %s\n", kubeconfigPath)
+	return nil
+}
+
+// waitForKubeletServerCert轮询csrName, 直到其被签发证书为止; 一旦CSR被拒绝或签发失败则立即返回错误。
+func waitForKubeletServerCert(client clientset.Interface, csrName string) ([]byte, error) {
+	var certPEM []byte
+	err := wait.PollImmediate(2*time.Second, kubeletServerCertWaitTimeout, func() (bool, error) {
+		csr, err := client.CertificatesV1().CertificateSigningRequests().Get(context.TODO(), csrName, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		for _, cond := range csr.Status.Conditions {
+			if cond.Type == certificatesv1.CertificateDenied || cond.Type == certificatesv1.CertificateFailed {
+				return false, errors.Errorf("kubelet serving证书的CSR %q 未能签发: %s", csrName, cond.Message)
+			}
+		}
+		if len(csr.Status.Certificate) == 0 {
+			return false, nil
+		}
+		certPEM = csr.Status.Certificate
+		return true, nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "等待CSR %q 签发kubelet serving证书超时", csrName)
+	}
+	return certPEM, nil
+}
+
+// parseIP把addr解析为一个单元素(或空)的net.IP切片, 供CSR的IP SAN使用; addr为空或不是合法IP时返回空切片。
+func parseIP(addr string) []net.IP {
+	if ip := net.ParseIP(addr); ip != nil {
+		return []net.IP{ip}
+	}
+	return nil
+}
+
+// buildKubeletServerKubeConfig构建一份引用已签发serving证书(以内嵌数据而非文件路径的形式)的kubeconfig,
+// 供kubelet-server阶段写出的kubelet-server.conf使用。
+func buildKubeletServerKubeConfig(cfg *kubeadmapi.InitConfiguration, certPEM, keyPEM []byte) (*clientcmdapi.Config, error) {
+	caCert, err := os.ReadFile(filepath.Join(cfg.CertificatesDir, kubeadmconstants.CACertName))
+	if err != nil {
+		return nil, errors.Wrap(err, "无法读取CA证书以写入kubelet-server.conf")
+	}
+
+	serverURL := fmt.Sprintf("https://%s", net.JoinHostPort(cfg.LocalAPIEndpoint.AdvertiseAddress, strconv.Itoa(int(cfg.LocalAPIEndpoint.BindPort))))
+
+	const contextName = "kubelet-server"
+	return &clientcmdapi.Config{
+		Clusters: map[string]*clientcmdapi.Cluster{
+			contextName: {
+				Server:                   serverURL,
+				CertificateAuthorityData: caCert,
+			},
+		},
+		Contexts: map[string]*clientcmdapi.Context{
+			contextName: {
+				Cluster:  contextName,
+				AuthInfo: contextName,
+			},
+		},
+		AuthInfos: map[string]*clientcmdapi.AuthInfo{
+			contextName: {
+				ClientCertificateData: certPEM,
+				ClientKeyData:         keyPEM,
+			},
+		},
+		CurrentContext: contextName,
+	}, nil
+}
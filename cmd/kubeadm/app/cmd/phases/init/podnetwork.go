@@ -0,0 +1,186 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"k8s.io/kubernetes/cmd/kubeadm/app/cmd/options"
+	"k8s.io/kubernetes/cmd/kubeadm/app/cmd/phases/workflow"
+	cmdutil "k8s.io/kubernetes/cmd/kubeadm/app/cmd/util"
+	"k8s.io/kubernetes/cmd/kubeadm/app/phases/addons/cni"
+	"k8s.io/kubernetes/cmd/kubeadm/app/util/apiclient"
+
+	"github.com/pkg/errors"
+)
+
+// podNetworkCheckInterval是轮询CNI DaemonSet就绪状态的间隔。
+const podNetworkCheckInterval = 2 * time.Second
+
+// podNetworkReadyTimeout是等待CNI DaemonSet就绪的总时长上限。
+const podNetworkReadyTimeout = 2 * time.Minute
+
+var podNetworkExample = cmdutil.Examples(`
+	# Install the calico Pod network addon using kubeadm's built-in manifest catalog.
+	kubeadm init phase pod-network --pod-network-addon=calico
+	`)
+
+// NewPodNetworkPhase 创建kubeadm工作流阶段，在addon阶段之后，按--pod-network-addon指定的插件从内置目录中
+// 取出已验证可用的清单，用集群的Pod网段与DNS域名渲染后通过server-side apply安装，并等待其DaemonSet在本
+// 控制平面节点上报告Ready——免去initDoneTempl中"请自行下载Pod网络插件YAML并apply"这一步手动操作。
+func NewPodNetworkPhase() workflow.Phase {
+	return workflow.Phase{
+		Name:    "pod-network",
+		Short:   "安装Pod网络插件(CNI)",
+		Long:    "按--pod-network-addon指定的插件，从kubeadm内置目录中安装一份已验证可用的Pod网络插件清单",
+		Example: podNetworkExample,
+		Run:     runPodNetwork,
+		InheritFlags: []string{
+			options.CfgPath,
+			"pod-network-addon",
+			"pod-network-addon-config",
+		},
+	}
+}
+
+// runPodNetwork 执行Pod网络插件安装逻辑
+func runPodNetwork(_ context.Context, c workflow.RunData) error {
+	data, ok := c.(InitData)
+	if !ok {
+		return errors.New("使用无效数据结构调用pod-network阶段")
+	}
+
+	addonName := data.PodNetworkAddon()
+	if addonName == "" || addonName == cni.None {
+		fmt.Fprintln(data.OutputWriter(), "[pod-network] 未指定 --pod-network-addon，跳过Pod网络插件安装")
+		return nil
+	}
+
+	cfg := data.Cfg()
+	if cfg.Networking.PodSubnet == "" {
+		return errors.Errorf("安装Pod网络插件 %q 需要先通过--pod-network-cidr设置Networking.PodSubnet", addonName)
+	}
+
+	addonSpec, knownAddon := cni.Get(addonName)
+
+	var manifestTemplate string
+	if override := data.PodNetworkAddonConfig(); override != "" {
+		manifest, err := loadManifestOverride(override)
+		if err != nil {
+			return errors.Wrapf(err, "无法加载--pod-network-addon-config指定的清单 %q", override)
+		}
+		manifestTemplate = manifest
+	} else {
+		if !knownAddon {
+			return errors.Errorf("未知的Pod网络插件 %q，内置目录中收录的插件有: %s", addonName, strings.Join(cni.KnownAddons(), ", "))
+		}
+		manifest, err := addonSpec.Manifest(cfg.KubernetesVersion)
+		if err != nil {
+			return err
+		}
+		manifestTemplate = manifest
+	}
+
+	rendered, err := cni.Render(manifestTemplate, cni.TemplateData{
+		PodCIDR:   cfg.Networking.PodSubnet,
+		DNSDomain: cfg.Networking.DNSDomain,
+	})
+	if err != nil {
+		return err
+	}
+	documents := cni.SplitManifests(rendered)
+
+	if data.DryRun() {
+		fmt.Fprintf(data.OutputWriter(), "[pod-network] 试运行: 将会应用 %d 个对象来安装 %q (已跳过实际apply与等待就绪)\n",
+			len(documents), addonName)
+		return nil
+	}
+
+	restConfig, err := clientcmd.BuildConfigFromFlags("", data.KubeConfigPath())
+	if err != nil {
+		return errors.Wrap(err, "无法从admin kubeconfig构建REST配置")
+	}
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return errors.Wrap(err, "无法创建dynamic client")
+	}
+
+	fmt.Fprintf(data.OutputWriter(), "[pod-network] 正在通过server-side apply安装 %q (%d 个对象)\n", addonName, len(documents))
+	if err := apiclient.ApplyManifests(dynamicClient, documents); err != nil {
+		return errors.Wrapf(err, "安装Pod网络插件 %q 失败", addonName)
+	}
+
+	if !knownAddon {
+		fmt.Fprintf(data.OutputWriter(), "[pod-network] %q 不在内置目录中，跳过等待DaemonSet就绪\n", addonName)
+		return nil
+	}
+
+	client, err := data.Client()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(data.OutputWriter(), "[pod-network] 等待DaemonSet %s/%s 就绪\n", addonSpec.Namespace, addonSpec.DaemonSetName)
+	return wait.PollImmediate(podNetworkCheckInterval, podNetworkReadyTimeout, func() (bool, error) {
+		ds, err := client.AppsV1().DaemonSets(addonSpec.Namespace).Get(context.TODO(), addonSpec.DaemonSetName, metav1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		return ds.Status.DesiredNumberScheduled > 0 && ds.Status.NumberReady == ds.Status.DesiredNumberScheduled, nil
+	})
+}
+
+// loadManifestOverride从--pod-network-addon-config指定的来源读取清单模板：以"http://"或"https://"开头的
+// 视为URL，否则视为本地文件路径。
+func loadManifestOverride(source string) (string, error) {
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		resp, err := http.Get(source)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return "", errors.Errorf("下载清单返回非预期的状态码: %s", resp.Status)
+		}
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return "", err
+		}
+		return string(body), nil
+	}
+
+	body, err := os.ReadFile(source)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
@@ -17,12 +17,14 @@ limitations under the License.
 package phases
 
 import (
+	"context"
 	"fmt"
 
 	"k8s.io/kubernetes/cmd/kubeadm/app/cmd/options"
 	"k8s.io/kubernetes/cmd/kubeadm/app/cmd/phases/workflow"
 	cmdutil "k8s.io/kubernetes/cmd/kubeadm/app/cmd/util"
 	"k8s.io/kubernetes/cmd/kubeadm/app/preflight"
+	utilruntime "k8s.io/kubernetes/cmd/kubeadm/app/util/runtime"
 
 	utilsexec "k8s.io/utils/exec"
 
@@ -39,20 +41,22 @@ var (
 // NewPreflightPhase 创建kubeadm工作流阶段，为新的控制平面节点实现预检检查。
 func NewPreflightPhase() workflow.Phase {
 	return workflow.Phase{
-		Name:    "preflight",
-		Short:   "运行 pre-flight checks",
-		Long:    "为 kubeadm init 运行 pre-flight checks",
-		Example: preflightExample,
-		Run:     runPreflight,
+		Name:         "preflight",
+		Short:        "运行 pre-flight checks",
+		Long:         "为 kubeadm init 运行 pre-flight checks",
+		Example:      preflightExample,
+		Run:          runPreflight,
+		NonResumable: true,
 		InheritFlags: []string{
 			options.CfgPath,
 			options.IgnorePreflightErrors,
+			"preflight-plugin-dir",
 		},
 	}
 }
 
 // runPreflight 执行预检逻辑
-func runPreflight(c workflow.RunData) error {
+func runPreflight(_ context.Context, c workflow.RunData) error {
 	data, ok := c.(InitData)
 	if !ok {
 		return errors.New("使用无效数据结构调用预检阶段")
@@ -64,15 +68,32 @@ func runPreflight(c workflow.RunData) error {
 		return err
 	}
 
-	if !data.DryRun() {
-		fmt.Println("[预检] 提取设置Kubernetes集群所需的镜像")
-		fmt.Println("[预检] 这可能需要一两分钟，具体取决于您的互联网连接速度")
-		fmt.Println("[预检] 您也可以使用 kubeadm config images pull")
-		if err := preflight.RunPullImagesCheck(utilsexec.New(), data.Cfg(), data.IgnorePreflightErrors()); err != nil {
+	// 容器运行时可能仍在监听套接字、却已经卡在relist循环里无法响应真正的容器/镜像操作；
+	// 在这里提前探测一遍，而不是放任它在后面的kubelet-start/wait-control-plane阶段才
+	// 表现为一段指不出原因的超时。
+	criSocket := data.Cfg().NodeRegistration.CRISocket
+	runtime, err := utilruntime.NewContainerRuntime(utilsexec.New(), criSocket)
+	if err != nil {
+		return errors.Wrapf(err, "无法通过CRI套接字 %q 连接容器运行时", criSocket)
+	}
+	runtimeHealthCheck := preflight.ContainerRuntimeHealthCheck{Runtime: runtime}
+	if err := preflight.RunChecks([]preflight.Checker{runtimeHealthCheck}, data.OutputWriter(), data.IgnorePreflightErrors()); err != nil {
+		return err
+	}
+
+	externalChecks, err := preflight.DiscoverExternalChecks(data.PreflightPluginDir(), data.Cfg())
+	if err != nil {
+		return err
+	}
+	if len(externalChecks) > 0 {
+		fmt.Printf("[预检] 发现 %d 个外部预检插件/声明式检查，正在执行\n", len(externalChecks))
+		checkers := make([]preflight.Checker, 0, len(externalChecks))
+		for _, check := range externalChecks {
+			checkers = append(checkers, check)
+		}
+		if err := preflight.RunChecks(checkers, data.OutputWriter(), data.IgnorePreflightErrors()); err != nil {
 			return err
 		}
-	} else {
-		fmt.Println("[预检] 需要提取所需的镜像 (例如 kubeadm config images pull")
 	}
 
 	return nil
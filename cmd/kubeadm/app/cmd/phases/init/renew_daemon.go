@@ -0,0 +1,218 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"k8s.io/kubernetes/cmd/kubeadm/app/cmd/phases/workflow"
+	kubeadmconstants "k8s.io/kubernetes/cmd/kubeadm/app/constants"
+	certsphase "k8s.io/kubernetes/cmd/kubeadm/app/phases/certs"
+	"k8s.io/kubernetes/cmd/kubeadm/app/phases/copycerts"
+	"k8s.io/kubernetes/cmd/kubeadm/app/util/pkiutil"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	errorsutil "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog/v2"
+)
+
+// RenewalDaemonOptions配置RunRenewalDaemon，对应`kubeadm certs renew --auto`暴露的
+// --threshold与--metrics-bind-address两个参数。
+type RenewalDaemonOptions struct {
+	// Threshold是证书剩余有效期低于该值时触发自动续期的阈值，例如720h(30天)。
+	Threshold time.Duration
+	// MetricsBindAddress是暴露kubeadm_cert_expiry_seconds指标的监听地址，留空则不启动
+	// 指标服务。
+	MetricsBindAddress string
+	// PollInterval是巡检全部证书的间隔；留空(0)时默认每小时巡检一次，足以覆盖绝大多数
+	// Threshold设置而不必频繁读盘。
+	PollInterval time.Duration
+}
+
+// certExpirySeconds按证书BaseName记录其距离NotAfter过期还剩余多少秒，负值表示已经过期。
+var certExpirySeconds = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "kubeadm_cert_expiry_seconds",
+		Help: "证书距离NotAfter过期时间还剩余的秒数，负值表示已过期",
+	},
+	[]string{"name"},
+)
+
+func init() {
+	prometheus.MustRegister(certExpirySeconds)
+}
+
+// RunRenewalDaemon实现`kubeadm certs renew --auto`：常驻巡检certsphase.GetDefaultCertList()中
+// 的每一张证书，任一证书的剩余有效期低于opts.Threshold时复用runCAPhase/runCertPhase重新签发该证
+// 书，touch对应的静态Pod清单让kubelet reload，并把kubeadm-certs Secret更新为磁盘上的最新内容。
+// 外部CA模式下kubeadm并不持有签发证书所需的私钥，因此只打印一条事件说明后直接返回，而不是反复
+// 巡检一个注定失败的操作。
+func RunRenewalDaemon(ctx context.Context, data InitData, opts RenewalDaemonOptions) error {
+	if data.ExternalCA() {
+		fmt.Printf("[证书续期] 外部CA模式下kubeadm没有签发证书所需的私钥，跳过自动续期守护进程\n")
+		return nil
+	}
+
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Hour
+	}
+
+	if opts.MetricsBindAddress != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{}))
+		server := &http.Server{Addr: opts.MetricsBindAddress, Handler: mux}
+		go func() {
+			if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				klog.Errorf("[证书续期] 指标服务异常退出: %v", err)
+			}
+		}()
+		defer server.Close()
+	}
+
+	if err := renewExpiringCerts(data, opts.Threshold); err != nil {
+		klog.Errorf("[证书续期] 首次巡检失败: %v", err)
+	}
+
+	ticker := time.NewTicker(opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := renewExpiringCerts(data, opts.Threshold); err != nil {
+				klog.Errorf("[证书续期] 本轮巡检失败: %v", err)
+			}
+		}
+	}
+}
+
+// renewExpiringCerts巡检一遍GetDefaultCertList()中的全部证书，上报kubeadm_cert_expiry_seconds
+// 指标，续期任何剩余有效期低于threshold的证书，并在有证书被续期时刷新kubeadm-certs Secret。
+func renewExpiringCerts(data InitData, threshold time.Duration) error {
+	var lastCACert *certsphase.KubeadmCert
+	var errs []error
+	renewedAny := false
+
+	for _, cert := range certsphase.GetDefaultCertList() {
+		if cert.CAName == "" {
+			lastCACert = cert
+		}
+
+		certData, err := pkiutil.TryLoadCertFromDisk(data.CertificateDir(), cert.BaseName)
+		if err != nil {
+			// 证书尚未生成(例如外部etcd模式下被跳过的etcd证书)，没有过期时间可汇报，跳过即可。
+			continue
+		}
+
+		remaining := time.Until(certData.NotAfter)
+		certExpirySeconds.WithLabelValues(cert.BaseName).Set(remaining.Seconds())
+
+		if remaining >= threshold {
+			continue
+		}
+
+		fmt.Printf("[证书续期] %s 将在 %s 内过期，开始自动续期\n", cert.BaseName, remaining.Round(time.Second))
+
+		var renew func(ctx context.Context, c workflow.RunData) error
+		if cert.CAName == "" {
+			renew = runCAPhase(cert)
+		} else {
+			renew = runCertPhase(cert, lastCACert)
+		}
+
+		if err := renew(context.Background(), data); err != nil {
+			errs = append(errs, errors.Wrapf(err, "续期证书 %s 失败", cert.BaseName))
+			continue
+		}
+		renewedAny = true
+
+		if err := touchStaticPodManifest(cert.BaseName); err != nil {
+			errs = append(errs, errors.Wrapf(err, "续期证书 %s 后触发静态Pod重载失败", cert.BaseName))
+		}
+	}
+
+	if renewedAny {
+		if err := refreshCertsSecret(data); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errorsutil.NewAggregate(errs)
+	}
+	return nil
+}
+
+// staticPodForCert把一张证书的BaseName映射到签发给它的静态Pod名称；没有对应静态Pod(例如CA证书
+// 或SA密钥)时返回空字符串。
+func staticPodForCert(baseName string) string {
+	switch baseName {
+	case kubeadmconstants.APIServerCertName, kubeadmconstants.APIServerKubeletClientCertName,
+		kubeadmconstants.APIServerEtcdClientCertName, kubeadmconstants.FrontProxyClientCertName:
+		return kubeadmconstants.KubeAPIServer
+	case kubeadmconstants.EtcdServerCertName, kubeadmconstants.EtcdPeerCertName, kubeadmconstants.EtcdHealthcheckClientCertName:
+		return kubeadmconstants.Etcd
+	default:
+		return ""
+	}
+}
+
+// touchStaticPodManifest更新证书对应静态Pod清单文件的mtime，促使kubelet把它当成一次清单变更
+// 重新同步、拉起使用新证书的容器，而不必等待kubelet自身下一次全量同步周期。
+func touchStaticPodManifest(certBaseName string) error {
+	podName := staticPodForCert(certBaseName)
+	if podName == "" {
+		return nil
+	}
+
+	manifestPath := filepath.Join(kubeadmconstants.GetStaticPodDirectory(), podName+".yaml")
+	now := time.Now()
+	if err := os.Chtimes(manifestPath, now, now); err != nil {
+		return errors.Wrapf(err, "无法touch静态Pod清单 %s", manifestPath)
+	}
+	return nil
+}
+
+// refreshCertsSecret把磁盘上最新的证书内容重新上传到kubeadm-certs Secret，复用upload-certs阶段
+// 使用的copycerts.UploadCerts，而不是单独重写一套Secret编码逻辑。没有配置--certificate-key时，
+// 说明这次init从未启用过该Secret，直接跳过而不是静默生成一个新key。
+func refreshCertsSecret(data InitData) error {
+	key := data.CertificateKey()
+	if key == "" {
+		klog.V(1).Infof("[证书续期] 未配置--certificate-key，跳过kubeadm-certs Secret刷新")
+		return nil
+	}
+
+	client, err := data.Client()
+	if err != nil {
+		return errors.Wrap(err, "无法获取用于刷新kubeadm-certs Secret的客户端")
+	}
+
+	if err := copycerts.UploadCerts(client, data.Cfg(), key); err != nil {
+		return errors.Wrap(err, "刷新kubeadm-certs Secret失败")
+	}
+	return nil
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package phases
 
 import (
+	"context"
 	"fmt"
 
 	"k8s.io/kubernetes/cmd/kubeadm/app/cmd/options"
@@ -45,7 +46,7 @@ func NewUploadCertsPhase() workflow.Phase {
 	}
 }
 
-func runUploadCerts(c workflow.RunData) error {
+func runUploadCerts(_ context.Context, c workflow.RunData) error {
 	data, ok := c.(InitData)
 	if !ok {
 		return errors.New("使用无效的数据结构调用upload-certs阶段")
@@ -17,9 +17,17 @@ limitations under the License.
 package phases
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"text/template"
 	"time"
 
@@ -35,6 +43,10 @@ import (
 	"github.com/pkg/errors"
 )
 
+// collectDiagnosticsEnvVar是一个开关性质的环境变量，设置为非空值时，wait-control-plane阶段在
+// 超时失败后会自动采集诊断信息并打包，而不是仅仅把要手动执行的命令打印给用户。
+const collectDiagnosticsEnvVar = "KUBEADM_COLLECT_DIAGS"
+
 var (
 	kubeletFailTempl = template.Must(template.New("init").Parse(dedent.Dedent(`
 	Unfortunately, an error has occurred:
@@ -60,6 +72,10 @@ var (
 		- 'crictl --runtime-endpoint {{ .Socket }} ps -a | grep kube | grep -v pause'
 		Once you have found the failing container, you can inspect its logs with:
 		- 'crictl --runtime-endpoint {{ .Socket }} logs CONTAINERID'
+{{ end }}
+{{ if .DiagnosticsBundle }}
+	A diagnostics bundle with the output of the commands above has been collected for you at:
+		{{ .DiagnosticsBundle }}
 {{ end }}
 	`)))
 )
@@ -75,7 +91,7 @@ func NewWaitControlPlanePhase() workflow.Phase {
 }
 
 // 创建一个Waiter然后执行WaitForKubeletAndFunc, 从预定义的常量读取超时, 超时以后则API Server挂了
-func runWaitControlPlanePhase(c workflow.RunData) error {
+func runWaitControlPlanePhase(_ context.Context, c workflow.RunData) error {
 	data, ok := c.(InitData)
 	if !ok {
 		return errors.New("wait-control-plane阶段使用无效的数据结构")
@@ -102,21 +118,36 @@ func runWaitControlPlanePhase(c workflow.RunData) error {
 
 	fmt.Printf("[wait-control-plane] 等待kubelet从目录中以静态Pods的形式启动控制平面 %q. 这可能需要 %v\n", data.ManifestDir(), timeout)
 
+	eventsDone := make(chan struct{})
+	go func() {
+		defer close(eventsDone)
+		renderWaitEvents(waiter.Events(), data.OutputWriter(), data.WaitEventsFile())
+	}()
+
 	if err := waiter.WaitForKubeletAndFunc(waiter.WaitForAPI); err != nil {
+		<-eventsDone
+		bundlePath, bundleErr := collectDiagnosticsOnFailure(data.DryRun(), data)
+		if bundleErr != nil {
+			klog.V(1).Infof("[wait-control-plane] 采集诊断信息包失败: %v", bundleErr)
+		}
+
 		context := struct {
-			Error    string
-			Socket   string
-			IsDocker bool
+			Error             string
+			Socket            string
+			IsDocker          bool
+			DiagnosticsBundle string
 		}{
-			Error:    fmt.Sprintf("%v", err),
-			Socket:   data.Cfg().NodeRegistration.CRISocket,
-			IsDocker: data.Cfg().NodeRegistration.CRISocket == kubeadmconstants.DefaultDockerCRISocket,
+			Error:             fmt.Sprintf("%v", err),
+			Socket:            data.Cfg().NodeRegistration.CRISocket,
+			IsDocker:          data.Cfg().NodeRegistration.CRISocket == kubeadmconstants.DefaultDockerCRISocket,
+			DiagnosticsBundle: bundlePath,
 		}
 
 		// 使用template库渲染错误信息
 		_ = kubeletFailTempl.Execute(data.OutputWriter(), context)
 		return errors.New("无法初始化Kubernetes群集")
 	}
+	<-eventsDone
 
 	return nil
 }
@@ -138,24 +169,176 @@ func printFilesIfDryRunning(data InitData) error {
 	for _, component := range kubeadmconstants.ControlPlaneComponents {
 		realPath := kubeadmconstants.GetStaticPodFilepath(component, manifestDir)
 		outputPath := kubeadmconstants.GetStaticPodFilepath(component, kubeadmconstants.GetStaticPodDirectory())
-		files = append(files, dryrunutil.NewFileToPrint(realPath, outputPath))
+		files = append(files, dryrunutil.NewFileToPrintWithDiff(realPath, outputPath, outputPath))
 	}
 	// Print kubelet config manifests
 	kubeletConfigFiles := []string{kubeadmconstants.KubeletConfigurationFileName, kubeadmconstants.KubeletEnvFileName}
 	for _, filename := range kubeletConfigFiles {
 		realPath := filepath.Join(manifestDir, filename)
 		outputPath := filepath.Join(kubeadmconstants.KubeletRunDirectory, filename)
-		files = append(files, dryrunutil.NewFileToPrint(realPath, outputPath))
+		files = append(files, dryrunutil.NewFileToPrintWithDiff(realPath, outputPath, outputPath))
 	}
 
+	if data.DryRunDiff() {
+		return dryrunutil.PrintDryRunDiff(files, data.OutputWriter())
+	}
 	return dryrunutil.PrintDryRunFiles(files, data.OutputWriter())
 }
 
 // newControlPlaneWaiter 返回一个新的Waiter，用于等待控制平面启动。
-func newControlPlaneWaiter(dryRun bool, timeout time.Duration, client clientset.Interface, out io.Writer) (apiclient.Waiter, error) {
+func newControlPlaneWaiter(dryRun bool, timeout time.Duration, client clientset.Interface, out io.Writer) (apiclient.WaiterWithEvents, error) {
 	if dryRun {
 		return dryrunutil.NewWaiter(), nil
 	}
 
 	return apiclient.NewKubeWaiter(client, timeout, out), nil
 }
+
+// renderWaitEvents消费events，为每个事件打印一行实时进度(组件、阶段、尝试次数、已耗时、最近一次错误)，
+// 当eventsFile非空时还会把同一份事件流追加写入该文件，每行一个JSON对象，供仪表盘或CI日志消费。
+// events关闭时函数返回。
+func renderWaitEvents(events <-chan apiclient.WaitEvent, out io.Writer, eventsFile string) {
+	var eventsWriter io.Writer
+	if eventsFile != "" {
+		f, err := os.OpenFile(eventsFile, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+		if err != nil {
+			klog.V(1).Infof("[wait-control-plane] 无法打开 --wait-events-file %q: %v", eventsFile, err)
+		} else {
+			defer f.Close()
+			eventsWriter = f
+		}
+	}
+
+	for event := range events {
+		status := "ok"
+		if event.LastError != "" {
+			status = "error: " + event.LastError
+		}
+		fmt.Fprintf(out, "[wait-control-plane] %-12s %-24s 第%d次尝试 (已耗时 %v): %s\n",
+			event.Component, event.Phase, event.Attempt, event.ElapsedSinceStart.Round(100*time.Millisecond), status)
+
+		if eventsWriter != nil {
+			line, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			eventsWriter.Write(append(line, '\n'))
+		}
+	}
+}
+
+// collectDiagnosticsOnFailure采集wait-control-plane失败时的诊断信息，并打包成
+// /var/log/kubeadm-diagnostics-<时间戳>.tar.gz。只有设置了环境变量 KUBEADM_COLLECT_DIAGS 时才会采集，
+// 默认不采集，避免在不需要时徒增超时后的等待时间。若处于试运行，则只生成一个空的桩归档——试运行时既没有真实的
+// kubelet，也没有真实的CRI套接字可供查询。
+func collectDiagnosticsOnFailure(dryRun bool, data InitData) (string, error) {
+	if os.Getenv(collectDiagnosticsEnvVar) == "" {
+		return "", nil
+	}
+
+	bundlePath := filepath.Join("/var/log", fmt.Sprintf("kubeadm-diagnostics-%d.tar.gz", time.Now().Unix()))
+	f, err := os.Create(bundlePath)
+	if err != nil {
+		return "", errors.Wrapf(err, "无法创建诊断信息包 %q", bundlePath)
+	}
+	defer f.Close()
+
+	gzw := gzip.NewWriter(f)
+	defer gzw.Close()
+	tw := tar.NewWriter(gzw)
+	defer tw.Close()
+
+	if dryRun {
+		return bundlePath, nil
+	}
+
+	addCommandOutputToBundle(tw, "systemctl-status-kubelet.txt", "systemctl", "status", "kubelet")
+	addCommandOutputToBundle(tw, "journalctl-kubelet.txt", "journalctl", "-xeu", "kubelet", "--since", "-10min")
+	addFileToBundle(tw, "kubelet-config.yaml", filepath.Join(kubeadmconstants.KubeletRunDirectory, kubeadmconstants.KubeletConfigurationFileName))
+
+	manifests, _ := filepath.Glob(filepath.Join(data.ManifestDir(), "*.yaml"))
+	for _, manifest := range manifests {
+		addFileToBundle(tw, filepath.Join("manifests", filepath.Base(manifest)), manifest)
+	}
+
+	criSocket := data.Cfg().NodeRegistration.CRISocket
+	addCommandOutputToBundle(tw, "crictl-ps.txt", "crictl", "-r", criSocket, "ps", "-a")
+	for _, name := range listKubeContainerNames(criSocket) {
+		addCommandOutputToBundle(tw, fmt.Sprintf("crictl-inspect-%s.txt", name), "crictl", "-r", criSocket, "inspect", name)
+		addCommandOutputToBundle(tw, fmt.Sprintf("crictl-logs-%s.txt", name), "crictl", "-r", criSocket, "logs", name)
+	}
+
+	return bundlePath, nil
+}
+
+// crictlContainerList是`crictl ps -a -o json`输出(CRI ListContainersResponse的JSON表示)中，
+// 枚举容器名称所需的最小字段子集。
+type crictlContainerList struct {
+	Containers []struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+	} `json:"containers"`
+}
+
+// listKubeContainerNames通过`crictl ps -a`枚举名字以kube-开头（pause容器除外）的容器名称。
+func listKubeContainerNames(criSocket string) []string {
+	out, err := exec.Command("crictl", "-r", criSocket, "ps", "-a", "--name", "kube-*", "-o", "json").Output()
+	if err != nil {
+		klog.V(1).Infof("[wait-control-plane] 无法通过crictl枚举kube-*容器: %v", err)
+		return nil
+	}
+	return parseCrictlContainerNames(out)
+}
+
+// parseCrictlContainerNames用encoding/json解析`crictl ps -a -o json`的输出，而不是手写字符串扫描
+// 去匹配`"name":"`这个标记——crictl使用标准库的JSON美化输出，字段之间可能有也可能没有空格
+// (`"name": "value"`与`"name":"value"`)，手写扫描一旦遇到前者就会悄悄匹配不到任何容器，
+// 诊断信息包因而缺少crictl inspect/logs的内容却不会报错。
+func parseCrictlContainerNames(out []byte) []string {
+	var list crictlContainerList
+	if err := json.Unmarshal(out, &list); err != nil {
+		klog.V(1).Infof("[wait-control-plane] 无法解析crictl ps -a的JSON输出: %v", err)
+		return nil
+	}
+
+	names := []string{}
+	for _, container := range list.Containers {
+		name := container.Metadata.Name
+		if strings.HasPrefix(name, "kube-") && !strings.Contains(name, "pause") {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// addCommandOutputToBundle运行一个命令，并将其标准输出（与一行错误提示，如果失败的话）写入归档中的name文件。
+func addCommandOutputToBundle(tw *tar.Writer, name string, command string, args ...string) {
+	out, err := exec.Command(command, args...).CombinedOutput()
+	if err != nil {
+		out = append(out, []byte(fmt.Sprintf("\n执行 %s %v 失败: %v\n", command, args, err))...)
+	}
+	_ = writeBundleEntry(tw, name, out)
+}
+
+// addFileToBundle把本机上的一个文件原样加入归档；文件不存在或不可读时，记录错误提示而不是让整个采集失败。
+func addFileToBundle(tw *tar.Writer, name, path string) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		content = []byte(fmt.Sprintf("无法读取 %s: %v\n", path, err))
+	}
+	_ = writeBundleEntry(tw, name, content)
+}
+
+func writeBundleEntry(tw *tar.Writer, name string, content []byte) error {
+	hdr := &tar.Header{
+		Name: name,
+		Mode: 0644,
+		Size: int64(len(content)),
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := io.Copy(tw, bytes.NewReader(content))
+	return err
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCrictlContainerNames(t *testing.T) {
+	cases := []struct {
+		name string
+		out  string
+		want []string
+	}{
+		{
+			name: "压缩的单行JSON",
+			out:  `{"containers":[{"id":"abc","metadata":{"name":"kube-apiserver","attempt":0}},{"id":"def","metadata":{"name":"pause","attempt":0}}]}`,
+			want: []string{"kube-apiserver"},
+		},
+		{
+			name: "标准库缩进美化输出, 冒号后带空格",
+			out: `{
+  "containers": [
+    {
+      "id": "abc",
+      "metadata": {
+        "name": "kube-scheduler",
+        "attempt": 0
+      }
+    },
+    {
+      "id": "ghi",
+      "metadata": {
+        "name": "kube-apiserver-pause",
+        "attempt": 0
+      }
+    }
+  ]
+}`,
+			want: []string{"kube-scheduler"},
+		},
+		{
+			name: "没有容器",
+			out:  `{"containers":[]}`,
+			want: []string{},
+		},
+		{
+			name: "无法解析的输出",
+			out:  `不是JSON`,
+			want: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := parseCrictlContainerNames([]byte(tc.out))
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("parseCrictlContainerNames() = %#v, 期望 %#v", got, tc.want)
+			}
+		})
+	}
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package phases
 
 import (
+	"context"
 	"fmt"
 
 	"k8s.io/kubernetes/cmd/kubeadm/app/cmd/phases/workflow"
@@ -34,7 +35,7 @@ func NewCheckEtcdPhase() workflow.Phase {
 	}
 }
 
-func runCheckEtcdPhase(c workflow.RunData) error {
+func runCheckEtcdPhase(_ context.Context, c workflow.RunData) error {
 	data, ok := c.(JoinData)
 	if !ok {
 		return errors.New("check-etcd phase invoked with an invalid data struct")
@@ -97,7 +97,7 @@ func getKubeletStartJoinData(c workflow.RunData) (*kubeadmapi.JoinConfiguration,
 
 // runKubeletStartJoinPhase 执行kubelet TLS引导进程。
 // 这个过程由kubelet执行，并在节点加入集群时按照节点授权者的要求使用一组专用凭证完成
-func runKubeletStartJoinPhase(c workflow.RunData) (returnErr error) {
+func runKubeletStartJoinPhase(_ context.Context, c workflow.RunData) (returnErr error) {
 	cfg, initCfg, tlsBootstrapCfg, err := getKubeletStartJoinData(c)
 	if err != nil {
 		return err
@@ -165,7 +165,10 @@ func runKubeletStartJoinPhase(c workflow.RunData) (returnErr error) {
 
 	// 尝试启动kubelet服务，以防它不活动
 	fmt.Println("[kubelet-start] Starting the kubelet")
-	kubeletphase.TryStartKubelet()
+	if err := kubeletphase.TryStartKubelet(); err != nil {
+		fmt.Printf(kubeadmJoinFailMsg, err)
+		return err
+	}
 
 	// 现在kubelet将执行TLS引导，将/etc/kubernetes/Bootstrap-kubelet.conf转换为/etc/kubernetes/kubelet.conf
 	// 等待kubelet创建/etc/kubernetes/kubelet.conf kubeconfig文件。如果此过程超时，显示一条用户友好的消息。
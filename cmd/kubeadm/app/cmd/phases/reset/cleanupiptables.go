@@ -0,0 +1,136 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/kubernetes/cmd/kubeadm/app/cmd/options"
+	"k8s.io/kubernetes/cmd/kubeadm/app/cmd/phases/workflow"
+
+	errorsutil "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/coreos/go-iptables/iptables"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+)
+
+// kubeProxyJumpRules列出kube-proxy用来把流量导入自己链的跳转规则；删除kube-proxy创建的链之前，
+// 必须先删掉这些引用，否则DeleteChain会因为链仍被跳转而失败。
+var kubeProxyJumpRules = []struct {
+	table, from, to string
+}{
+	{"nat", "PREROUTING", "KUBE-SERVICES"},
+	{"nat", "OUTPUT", "KUBE-SERVICES"},
+	{"nat", "POSTROUTING", "KUBE-POSTROUTING"},
+	{"filter", "FORWARD", "KUBE-FORWARD"},
+	{"filter", "INPUT", "KUBE-EXTERNAL-SERVICES"},
+	{"filter", "FORWARD", "KUBE-SERVICES"},
+	{"mangle", "PREROUTING", "KUBE-SERVICES"},
+}
+
+// kubeProxyChains列出kube-proxy在iptables模式下，于nat/filter/mangle三张表里创建的链。
+var kubeProxyChains = []struct {
+	table, chain string
+}{
+	{"nat", "KUBE-SERVICES"},
+	{"nat", "KUBE-EXTERNAL-SERVICES"},
+	{"nat", "KUBE-NODEPORTS"},
+	{"nat", "KUBE-POSTROUTING"},
+	{"nat", "KUBE-MARK-MASQ"},
+	{"nat", "KUBE-MARK-DROP"},
+	{"filter", "KUBE-FORWARD"},
+	{"filter", "KUBE-EXTERNAL-SERVICES"},
+	{"filter", "KUBE-SERVICES"},
+	{"filter", "KUBE-NODEPORTS"},
+	{"mangle", "KUBE-MARK-MASQ"},
+	{"mangle", "KUBE-SERVICES"},
+}
+
+// NewCleanupIPTablesPhase 创建一个kubeadm Workflow的隐藏阶段，在cleanup-node之后运行，按需清除
+// kube-proxy在iptables模式下遗留的全部KUBE-*链，取代此前只打印iptablesCleanupInstructions让
+// 操作者自行执行iptables命令的做法。
+func NewCleanupIPTablesPhase() workflow.Phase {
+	return workflow.Phase{
+		Name:   "cleanup-iptables",
+		Short:  "清除kube-proxy遗留的iptables规则",
+		Long:   "当检测到(或通过--proxy-cleanup强制指定)集群使用iptables模式的kube-proxy时，清除其创建的全部KUBE-*链",
+		Run:    runCleanupIPTablesPhase,
+		Hidden: true,
+		InheritFlags: []string{
+			options.KubeconfigPath,
+			"proxy-cleanup",
+		},
+	}
+}
+
+func runCleanupIPTablesPhase(ctx context.Context, c workflow.RunData) error {
+	r, ok := c.(resetData)
+	if !ok {
+		return errors.New("无效的数据结构调用了cleanup-iptables阶段")
+	}
+
+	mode, err := detectProxyMode(ctx, r.Client(), r.ProxyCleanupMode())
+	if err != nil {
+		klog.Warningf("[重置] 无法确定kube-proxy的代理模式，跳过iptables规则清理: %v", err)
+		return nil
+	}
+	if mode != "iptables" {
+		fmt.Printf("[重置] 检测到的代理模式为 %q，跳过iptables规则清理\n", mode)
+		return nil
+	}
+
+	ipt, err := iptables.New()
+	if err != nil {
+		return errors.Wrap(err, "无法初始化iptables客户端")
+	}
+
+	var errs []error
+	for _, rule := range kubeProxyJumpRules {
+		exists, err := ipt.Exists(rule.table, rule.from, "-j", rule.to)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "检查规则 %s/%s -> %s 是否存在时出错", rule.table, rule.from, rule.to))
+			continue
+		}
+		if !exists {
+			continue
+		}
+		if err := ipt.Delete(rule.table, rule.from, "-j", rule.to); err != nil {
+			errs = append(errs, errors.Wrapf(err, "删除规则 %s/%s -> %s 失败", rule.table, rule.from, rule.to))
+		}
+	}
+
+	for _, chain := range kubeProxyChains {
+		if err := ipt.ClearChain(chain.table, chain.chain); err != nil {
+			errs = append(errs, errors.Wrapf(err, "清空链 %s/%s 失败", chain.table, chain.chain))
+			continue
+		}
+		if err := ipt.DeleteChain(chain.table, chain.chain); err != nil {
+			// 链仍被别的链跳转(例如不在kubeProxyJumpRules覆盖范围内的自定义规则)时DeleteChain会失败，
+			// 这里只记录警告：链已经被清空，不再转发任何流量，删除空壳链本身不是关键步骤。
+			klog.Warningf("[重置] 链 %s/%s 已清空，但删除该链失败: %v", chain.table, chain.chain, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errorsutil.NewAggregate(errs)
+	}
+
+	fmt.Println("[重置] 已清除kube-proxy遗留的iptables规则")
+	return nil
+}
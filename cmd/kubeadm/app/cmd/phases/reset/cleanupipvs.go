@@ -0,0 +1,89 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/kubernetes/cmd/kubeadm/app/cmd/options"
+	"k8s.io/kubernetes/cmd/kubeadm/app/cmd/phases/workflow"
+
+	errorsutil "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/moby/ipvs"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+)
+
+// NewCleanupIPVSPhase 创建一个kubeadm Workflow的隐藏阶段，在cleanup-node之后运行，按需清除
+// kube-proxy在IPVS模式下创建的全部虚拟服务，取代此前只打印iptablesCleanupInstructions让
+// 操作者自行执行ipvsadm --clear的做法。
+func NewCleanupIPVSPhase() workflow.Phase {
+	return workflow.Phase{
+		Name:   "cleanup-ipvs",
+		Short:  "清除kube-proxy遗留的IPVS虚拟服务",
+		Long:   "当检测到(或通过--proxy-cleanup强制指定)集群使用IPVS模式的kube-proxy时，清除其创建的全部IPVS虚拟服务",
+		Run:    runCleanupIPVSPhase,
+		Hidden: true,
+		InheritFlags: []string{
+			options.KubeconfigPath,
+			"proxy-cleanup",
+		},
+	}
+}
+
+func runCleanupIPVSPhase(ctx context.Context, c workflow.RunData) error {
+	r, ok := c.(resetData)
+	if !ok {
+		return errors.New("无效的数据结构调用了cleanup-ipvs阶段")
+	}
+
+	mode, err := detectProxyMode(ctx, r.Client(), r.ProxyCleanupMode())
+	if err != nil {
+		klog.Warningf("[重置] 无法确定kube-proxy的代理模式，跳过IPVS虚拟服务清理: %v", err)
+		return nil
+	}
+	if mode != "ipvs" {
+		fmt.Printf("[重置] 检测到的代理模式为 %q，跳过IPVS虚拟服务清理\n", mode)
+		return nil
+	}
+
+	handle, err := ipvs.New("")
+	if err != nil {
+		return errors.Wrap(err, "无法初始化IPVS客户端，请确认ip_vs内核模块已加载")
+	}
+
+	services, err := handle.GetServices()
+	if err != nil {
+		return errors.Wrap(err, "无法列出现有的IPVS虚拟服务")
+	}
+
+	var errs []error
+	for _, service := range services {
+		if err := handle.DelService(service); err != nil {
+			errs = append(errs, errors.Wrapf(err, "删除虚拟服务 %s:%d 失败", service.Address, service.Port))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errorsutil.NewAggregate(errs)
+	}
+
+	fmt.Printf("[重置] 已清除 %d 个kube-proxy遗留的IPVS虚拟服务\n", len(services))
+	return nil
+}
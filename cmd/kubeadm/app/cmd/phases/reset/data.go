@@ -36,4 +36,14 @@ type resetData interface {
 	AddDirsToClean(dirs ...string)
 	CertificatesDir() string
 	CRISocketPath() string
+	RemoveExternalEtcdMember() bool
+	RemoveExternalEtcdMemberDryRun() bool
+	EtcdEndpoints() []string
+	EtcdCAFile() string
+	EtcdCertFile() string
+	EtcdKeyFile() string
+	PreserveEtcdDataDir() bool
+	EtcdSnapshotPath() string
+	ProxyCleanupMode() string
+	KubeConfigPath() string
 }
@@ -0,0 +1,36 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"testing"
+
+	kubeadmapi "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm"
+)
+
+func TestGetEtcdDataDirFromConfig(t *testing.T) {
+	cfg := &kubeadmapi.InitConfiguration{}
+	cfg.Etcd.Local = &kubeadmapi.LocalEtcd{DataDir: "/var/lib/etcd-fixture"}
+
+	dataDir, err := getEtcdDataDir("", cfg)
+	if err != nil {
+		t.Fatalf("getEtcdDataDir返回了意料之外的错误: %v", err)
+	}
+	if dataDir != "/var/lib/etcd-fixture" {
+		t.Errorf("getEtcdDataDir() = %q, 期望为 %q", dataDir, "/var/lib/etcd-fixture")
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"go.etcd.io/etcd/client/pkg/v3/transport"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/snapshot"
+	"go.uber.org/zap"
+
+	kubeadmconstants "k8s.io/kubernetes/cmd/kubeadm/app/constants"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+)
+
+// localEtcdClientEndpoint是stacked etcd静态Pod默认监听的loopback客户端Endpoint。
+const localEtcdClientEndpoint = "https://127.0.0.1:2379"
+
+// etcdSnapshotTimeout是保存并校验一份etcd快照允许耗费的最长时长。
+const etcdSnapshotTimeout = 1 * time.Minute
+
+// snapshotLocalEtcd对本机的stacked etcd成员执行一次etcd v3快照, 并写入snapshotPath, 随后立即
+// 通过snapshot.Status重新读取该快照文件以校验其哈希, 从而在--preserve-data-dir清空数据目录之前
+// 给操作者留下一份可验证完整性的恢复手段。parentCtx来自Runner对remove-etcd-member阶段的调度,
+// 如果同一调度层级中的其他阶段失败，这次快照也会随之被取消，不会成为无人等待的孤立操作。
+func snapshotLocalEtcd(parentCtx context.Context, r resetData, snapshotPath string) error {
+	tlsInfo := transport.TLSInfo{
+		CertFile:      filepath.Join(r.CertificatesDir(), kubeadmconstants.EtcdHealthcheckClientCertName),
+		KeyFile:       filepath.Join(r.CertificatesDir(), kubeadmconstants.EtcdHealthcheckClientKeyName),
+		TrustedCAFile: filepath.Join(r.CertificatesDir(), kubeadmconstants.EtcdCACertName),
+	}
+	tlsConfig, err := tlsInfo.ClientConfig()
+	if err != nil {
+		return errors.Wrap(err, "无法构建用于保存etcd快照的TLS配置")
+	}
+
+	lg, err := zap.NewProduction()
+	if err != nil {
+		return errors.Wrap(err, "无法创建etcd快照客户端的日志记录器")
+	}
+	defer lg.Sync()
+
+	ctx, cancel := context.WithTimeout(parentCtx, etcdSnapshotTimeout)
+	defer cancel()
+
+	cfg := clientv3.Config{
+		Endpoints:   []string{localEtcdClientEndpoint},
+		DialTimeout: etcdSnapshotTimeout,
+		TLS:         tlsConfig,
+	}
+	if err := snapshot.Save(ctx, lg, cfg, snapshotPath); err != nil {
+		return errors.Wrapf(err, "无法将etcd快照保存到 %s", snapshotPath)
+	}
+
+	status, err := snapshot.Status(lg, snapshotPath)
+	if err != nil {
+		return errors.Wrapf(err, "无法校验快照 %s 的完整性", snapshotPath)
+	}
+	klog.V(1).Infof("[重置] etcd快照 %s 校验通过(哈希 %d, 共 %d 个键, 大小 %d 字节)",
+		snapshotPath, status.Hash, status.TotalKey, status.TotalSize)
+	return nil
+}
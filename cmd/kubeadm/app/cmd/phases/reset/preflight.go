@@ -17,9 +17,11 @@ limitations under the License.
 package phases
 
 import (
+	"context"
 	"bufio"
 	"errors"
 	"fmt"
+	"os"
 	"strings"
 
 	"k8s.io/kubernetes/cmd/kubeadm/app/cmd/options"
@@ -27,14 +29,20 @@ import (
 	"k8s.io/kubernetes/cmd/kubeadm/app/preflight"
 )
 
+// resetConfirmEnvVar是可以用来代替交互式确认的环境变量：把它设置成当前节点的主机名，即表示
+// 已经确认过重置操作，runPreflight会跳过bufio.Scanner那行阻塞式的stdin读取，方便CI、Ansible
+// 等以非tty方式驱动reset。
+const resetConfirmEnvVar = "KUBEADM_RESET_CONFIRM"
+
 // NewPreflightPhase 创建kubeadm工作流阶段，执行pre-flight前重置检查
 func NewPreflightPhase() workflow.Phase {
 	return workflow.Phase{
-		Name:    "preflight",
-		Aliases: []string{"pre-flight"},
-		Short:   "运行重置操作的预检",
-		Long:    "为 kubeadm reset 运行预检",
-		Run:     runPreflight,
+		Name:         "preflight",
+		Aliases:      []string{"pre-flight"},
+		Short:        "运行重置操作的预检",
+		Long:         "为 kubeadm reset 运行预检",
+		Run:          runPreflight,
+		NonResumable: true,
 		InheritFlags: []string{
 			options.IgnorePreflightErrors,
 			options.ForceReset,
@@ -43,27 +51,56 @@ func NewPreflightPhase() workflow.Phase {
 }
 
 // runPreflight 执行预检逻辑
-func runPreflight(c workflow.RunData) error {
+func runPreflight(_ context.Context, c workflow.RunData) error {
 	r, ok := c.(resetData)
 	if !ok {
 		return errors.New("用无效的数据结构调用了预检阶段")
 	}
 
 	if !r.ForceReset() {
-		fmt.Println("[重置] 警告: kubeadm init 或 kubeadm join 对此主机所做的更改将被还原")
-		fmt.Print("[重置] 确定要开始吗? [y/N]: ")
-
-		s := bufio.NewScanner(r.InputReader())
-		s.Scan()
-		
-		if err := s.Err(); err != nil {
+		confirmed, err := resetConfirmedByEnv()
+		if err != nil {
 			return err
 		}
-		if strings.ToLower(s.Text()) != "y" {
-			return errors.New("中止重置操作")
+
+		if !confirmed {
+			fmt.Println("[重置] 警告: kubeadm init 或 kubeadm join 对此主机所做的更改将被还原")
+			fmt.Print("[重置] 确定要开始吗? [y/N]: ")
+
+			s := bufio.NewScanner(r.InputReader())
+			s.Scan()
+
+			if err := s.Err(); err != nil {
+				return err
+			}
+			if strings.ToLower(s.Text()) != "y" {
+				return errors.New("中止重置操作")
+			}
 		}
 	}
 
 	fmt.Println("[预检] 运行预检")
 	return preflight.RunRootCheckOnly(r.IgnorePreflightErrors())
 }
+
+// resetConfirmedByEnv检查KUBEADM_RESET_CONFIRM环境变量: 未设置时返回(false, nil)，调用方应回退到
+// 交互式确认；设置且与本机主机名一致时视为已经确认过，返回(true, nil)，跳过交互式确认；设置但与
+// 主机名不一致时，大概率是把这条环境变量误用到了别的主机上，直接返回错误中止重置，而不是当作
+// 未设置静默地退回交互式确认，以免在自动化场景下把本该阻止的误操作又悄悄放行。
+func resetConfirmedByEnv() (bool, error) {
+	confirm, ok := os.LookupEnv(resetConfirmEnvVar)
+	if !ok {
+		return false, nil
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return false, fmt.Errorf("无法获取本机主机名以校验%s: %w", resetConfirmEnvVar, err)
+	}
+	if confirm != hostname {
+		return false, fmt.Errorf("%s=%q与本机主机名%q不匹配，为安全起见中止重置操作", resetConfirmEnvVar, confirm, hostname)
+	}
+
+	fmt.Printf("[重置] 检测到%s与本机主机名匹配，跳过交互式确认\n", resetConfirmEnvVar)
+	return true, nil
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// kubeProxyConfigMapName、kubeProxyConfigMapNamespace是kube-proxy自身部署时创建的ConfigMap的
+// 名称与所在命名空间，其中"config.conf"这个key下是一份完整的KubeProxyConfiguration。
+const (
+	kubeProxyConfigMapName      = "kube-proxy"
+	kubeProxyConfigMapNamespace = "kube-system"
+)
+
+// kubeProxyConfig是KubeProxyConfiguration中我们唯一关心的字段；mode为空字符串时kube-proxy自身
+// 默认使用iptables模式。
+type kubeProxyConfig struct {
+	Mode string `json:"mode"`
+}
+
+// detectProxyMode根据override(来自--proxy-cleanup)决定cleanup-iptables/cleanup-ipvs两个阶段
+// 各自应不应该执行：
+//   - override为"none"时返回空字符串，两个阶段都跳过；
+//   - override为"iptables"或"ipvs"时原样返回，不查集群；
+//   - override为"auto"(默认)时尝试从kube-proxy ConfigMap里读取实际使用的代理模式，client为nil
+//     或获取/解析失败时返回空字符串与一个warning error，两个阶段都会打印提示后跳过，而不是盲目
+//     清理一个没有把握的模式。
+func detectProxyMode(ctx context.Context, client clientset.Interface, override string) (string, error) {
+	switch override {
+	case "none":
+		return "", nil
+	case "iptables", "ipvs":
+		return override, nil
+	}
+
+	if client == nil {
+		return "", errors.New("没有可用的集群客户端，无法从kube-proxy ConfigMap中探测代理模式")
+	}
+
+	cm, err := client.CoreV1().ConfigMaps(kubeProxyConfigMapNamespace).Get(ctx, kubeProxyConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		return "", errors.Wrapf(err, "无法获取%s/%s ConfigMap", kubeProxyConfigMapNamespace, kubeProxyConfigMapName)
+	}
+
+	var cfg kubeProxyConfig
+	if err := yaml.Unmarshal([]byte(cm.Data["config.conf"]), &cfg); err != nil {
+		return "", errors.Wrap(err, "无法解析kube-proxy ConfigMap中的config.conf")
+	}
+
+	if cfg.Mode == "" {
+		return "iptables", nil
+	}
+	return cfg.Mode, nil
+}
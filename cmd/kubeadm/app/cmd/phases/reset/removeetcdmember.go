@@ -17,7 +17,7 @@ limitations under the License.
 package phases
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"path/filepath"
 
@@ -26,8 +26,10 @@ import (
 	"k8s.io/kubernetes/cmd/kubeadm/app/cmd/phases/workflow"
 	kubeadmconstants "k8s.io/kubernetes/cmd/kubeadm/app/constants"
 	etcdphase "k8s.io/kubernetes/cmd/kubeadm/app/phases/etcd"
+	etcdutil "k8s.io/kubernetes/cmd/kubeadm/app/util/etcd"
 	utilstaticpod "k8s.io/kubernetes/cmd/kubeadm/app/util/staticpod"
 
+	"github.com/pkg/errors"
 	"k8s.io/klog/v2"
 )
 
@@ -40,11 +42,20 @@ func NewRemoveETCDMemberPhase() workflow.Phase {
 		Run:   runRemoveETCDMemberPhase,
 		InheritFlags: []string{
 			options.KubeconfigPath,
+			"remove-external-etcd-member",
+			"remove-external-etcd-member-dry-run",
+			"etcd-endpoints",
+			"etcd-cafile",
+			"etcd-certfile",
+			"etcd-keyfile",
+			options.ForceReset,
+			"preserve-data-dir",
+			"etcd-snapshot",
 		},
 	}
 }
 
-func runRemoveETCDMemberPhase(c workflow.RunData) error {
+func runRemoveETCDMemberPhase(ctx context.Context, c workflow.RunData) error {
 	r, ok := c.(resetData)
 	if !ok {
 		return errors.New("无效的数据结构调用了 remove-etcd-member-phase 阶段")
@@ -58,17 +69,90 @@ func runRemoveETCDMemberPhase(c workflow.RunData) error {
 	// 获取到 etcd 的数据目录
 	etcdDataDir, err := getEtcdDataDir(etcdManifestPath, cfg)
 	if err == nil {
-		r.AddDirsToClean(etcdDataDir)
+		if snapshotPath := r.EtcdSnapshotPath(); snapshotPath != "" {
+			if err := snapshotLocalEtcd(ctx, r, snapshotPath); err != nil {
+				if !r.ForceReset() {
+					return errors.Wrapf(err, "保存etcd快照失败，已中止重置；如需在快照失败时仍然继续，请同时指定--force")
+				}
+				klog.Warningf("[重置] 保存etcd快照失败，但已指定--force，继续执行重置: %v", err)
+			} else {
+				fmt.Printf("[重置] 已将etcd快照保存到 %s\n", snapshotPath)
+			}
+		}
+
+		if r.PreserveEtcdDataDir() {
+			fmt.Printf("[重置] 已指定--preserve-data-dir，保留etcd数据目录 %s，不会清除其内容\n", etcdDataDir)
+		} else {
+			r.AddDirsToClean(etcdDataDir)
+		}
 		if cfg != nil {
 			if err := etcdphase.RemoveStackedEtcdMemberFromCluster(r.Client(), cfg); err != nil {
 				klog.Warningf("[重置] 无法删除 etcd 成员: %v，请使用 etcdctl 手动删除此 etcd 成员", err)
 			}
 		}
-	} else {
+		return nil
+	}
+
+	if !r.RemoveExternalEtcdMember() {
 		fmt.Println("[重置] 没有发现 etcd 的配置。可能是外部的 etcd。")
 		fmt.Println("[重置] 请手动重置 etcd 以防止进一步的问题")
+		fmt.Println("[重置] 如果这是外部 etcd 集群的一个成员节点，可以加上 --remove-external-etcd-member，" +
+			"并配合 --etcd-endpoints/--etcd-cafile/--etcd-certfile/--etcd-keyfile 让本阶段自动移除该成员")
+		return nil
+	}
+
+	return removeExternalEtcdMember(r, cfg)
+}
+
+// removeExternalEtcdMember 在--remove-external-etcd-member被设置时, 使用--etcd-endpoints等TLS材料
+// 连接外部etcd集群, 定位本机对应的成员并将其移除, 使外部etcd场景下的kubeadm reset与stacked etcd场景
+// 一样, 不需要用户再手动执行etcdctl member remove。
+func removeExternalEtcdMember(r resetData, cfg *kubeadmapi.InitConfiguration) error {
+	endpoints := r.EtcdEndpoints()
+	if len(endpoints) == 0 {
+		return errors.New("--remove-external-etcd-member 需要同时指定 --etcd-endpoints")
+	}
+
+	client, err := etcdutil.New(endpoints, r.EtcdCAFile(), r.EtcdCertFile(), r.EtcdKeyFile())
+	if err != nil {
+		return errors.Wrap(err, "无法创建外部 etcd 客户端")
+	}
+
+	members, err := client.ListMembers()
+	if err != nil {
+		return errors.Wrap(err, "无法获取外部 etcd 集群的成员列表")
+	}
+
+	if r.RemoveExternalEtcdMemberDryRun() {
+		fmt.Println("[重置] 试运行: 外部 etcd 集群当前的成员列表:")
+		for _, member := range members {
+			fmt.Printf("[重置]   - %s (%s)\n", member.Name, member.PeerURL)
+		}
+		return nil
+	}
+
+	if cfg == nil || cfg.LocalAPIEndpoint.AdvertiseAddress == "" {
+		klog.Warningln("[重置] 缺少可用的 kubeadm 配置, 无法确定本机在外部 etcd 集群中的 peer URL，" +
+			"跳过自动移除，请使用 etcdctl 手动移除此 etcd 成员")
+		return nil
+	}
+
+	peerURL := etcdutil.GetPeerURL(&cfg.LocalAPIEndpoint)
+	id, err := client.GetMemberID(peerURL)
+	if err != nil {
+		if errors.Is(err, etcdutil.ErrNoMemberIDForPeerURL) {
+			klog.Warningf("[重置] 外部 etcd 集群中没有找到 peer URL 为 %s 的成员，可能已经被移除", peerURL)
+			return nil
+		}
+		return errors.Wrap(err, "无法获取本机在外部 etcd 集群中的成员 ID")
+	}
+
+	if _, err := client.RemoveMember(id); err != nil {
+		klog.Warningf("[重置] 无法移除外部 etcd 成员: %v，请使用 etcdctl 手动移除此 etcd 成员", err)
+		return nil
 	}
 
+	fmt.Printf("[重置] 已从外部 etcd 集群中移除成员 %s\n", peerURL)
 	return nil
 }
 
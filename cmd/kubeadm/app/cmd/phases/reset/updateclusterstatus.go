@@ -17,34 +17,56 @@ limitations under the License.
 package phases
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
 
 	"k8s.io/kubernetes/cmd/kubeadm/app/cmd/phases/workflow"
 	kubeadmconstants "k8s.io/kubernetes/cmd/kubeadm/app/constants"
+	"k8s.io/kubernetes/cmd/kubeadm/app/phases/clusterstatus"
+
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/clientcmd"
 )
 
 // NewUpdateClusterStatus 创建一个 kubeadm Workflow 的 Phase 执行 update-cluster-status
 func NewUpdateClusterStatus() workflow.Phase {
 	return workflow.Phase{
 		Name:  "update-cluster-status",
-		Short: "在 ClusterStatus 对象中删除这个节点 (废弃)",
+		Short: "从ClusterStatus自定义资源中删除这个节点",
 		Run:   runUpdateClusterStatus,
 	}
 }
 
-func runUpdateClusterStatus(c workflow.RunData) error {
+func runUpdateClusterStatus(ctx context.Context, c workflow.RunData) error {
 	r, ok := c.(resetData)
 	if !ok {
 		return errors.New("无效的数据结构调用了 update-cluster-status 阶段")
 	}
 
 	cfg := r.Cfg()
-	if isControlPlane() && cfg != nil {
-		fmt.Println("update-cluster-status 阶段是废弃的功能，在未来的代码中可能会被移除" +
-			"目前它不执行任何操作")
+	if !isControlPlane() || cfg == nil {
+		return nil
+	}
+
+	restConfig, err := clientcmd.BuildConfigFromFlags("", r.KubeConfigPath())
+	if err != nil {
+		// 节点已经没有可用的admin kubeconfig(例如已经执行过一部分重置)，没有ClusterStatus可以
+		// 更新，与历史行为一样跳过而不是报错中止重置流程。
+		fmt.Printf("[reset] 无法从admin kubeconfig构建REST配置，跳过ClusterStatus更新: %v\n", err)
+		return nil
+	}
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		fmt.Printf("[reset] 无法创建dynamic client，跳过ClusterStatus更新: %v\n", err)
+		return nil
+	}
+
+	if err := clusterstatus.RemoveMember(ctx, dynamicClient, cfg.NodeRegistration.Name); err != nil {
+		return err
 	}
+	fmt.Printf("[reset] 已从ClusterStatus中删除节点 %s\n", cfg.NodeRegistration.Name)
 	return nil
 }
 
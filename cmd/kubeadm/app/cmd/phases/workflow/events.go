@@ -0,0 +1,92 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// PhaseEventStatus枚举了一次PhaseEvent所能描述的阶段状态迁移。
+type PhaseEventStatus string
+
+const (
+	// PhaseEventStatusRunning表示阶段刚开始执行。
+	PhaseEventStatusRunning PhaseEventStatus = "running"
+	// PhaseEventStatusSucceeded表示阶段已经执行成功。
+	PhaseEventStatusSucceeded PhaseEventStatus = "succeeded"
+	// PhaseEventStatusFailed表示阶段执行失败。
+	PhaseEventStatusFailed PhaseEventStatus = "failed"
+	// PhaseEventStatusSkipped表示在Runner.Options.DryRun下，阶段的RunIf判断其本不会执行。
+	PhaseEventStatusSkipped PhaseEventStatus = "skipped"
+	// PhaseEventStatusPlanned表示在Runner.Options.DryRun下，阶段本应执行，但实际的Run被跳过，
+	// 这条事件代表的只是一份执行计划，而不是阶段真的运行过。
+	PhaseEventStatusPlanned PhaseEventStatus = "planned"
+)
+
+// PhaseEvent是Runner在执行阶段期间发出的一条结构化记录，供CI/自动化场景(如e2e测试框架、
+// 集群升级工具)可靠地解析执行进度，而不必抓取面向人类、且已本地化的文本提示。
+type PhaseEvent struct {
+	// Phase是该事件所属的顶层阶段名称。
+	Phase string `json:"phase"`
+	// SubPhase是该事件所属阶段的完整嵌套路径(与--skip-phases接受的值一致)；仅当该阶段是某个
+	// 顶层阶段的子阶段时才非空。
+	SubPhase string `json:"subPhase,omitempty"`
+	// Status是该阶段此刻的状态迁移。
+	Status PhaseEventStatus `json:"status"`
+	// StartedAt是该阶段开始执行的时间。
+	StartedAt time.Time `json:"startedAt"`
+	// DurationMS是该阶段从开始执行到此事件为止所经过的毫秒数；仅在Status为succeeded/failed时有意义。
+	DurationMS int64 `json:"durationMS,omitempty"`
+	// Err是阶段执行失败时的错误信息；仅当Status为failed时非空。
+	Err string `json:"err,omitempty"`
+	// Node是执行该阶段的主机名，通过os.Hostname()获取；多节点场景下(如逐节点join)用于区分
+	// 事件分别来自哪台主机。获取主机名失败时留空。
+	Node string `json:"node,omitempty"`
+	// Artifacts列出该阶段执行过程中产生的文件或其他制品路径，供自动化场景消费；目前没有阶段
+	// 填充此字段，预留以便将来扩展。
+	Artifacts []string `json:"artifacts,omitempty"`
+}
+
+// EventEmitter可以被RunData可选地实现(通过EventEmitterProvider)，使Runner在执行每个阶段时，
+// 除了打印面向人类的文本之外，还把该阶段的状态迁移作为一条PhaseEvent发送出去。
+type EventEmitter interface {
+	EmitPhaseEvent(event PhaseEvent)
+}
+
+// EventEmitterProvider可以被RunData可选地实现, 为Runner提供一个EventEmitter。没有实现该接口的
+// RunData不会产生任何结构化事件，Runner的行为与此前完全一致。
+type EventEmitterProvider interface {
+	EventEmitter() EventEmitter
+}
+
+// NDJSONEventEmitter是EventEmitter的一个具体实现: 把每个PhaseEvent序列化为一行JSON(即换行分隔
+// 的JSON, NDJSON)写入Writer，供--output=json/--output=yaml等自动化场景按行增量解析。
+type NDJSONEventEmitter struct {
+	Writer io.Writer
+}
+
+// EmitPhaseEvent实现EventEmitter。序列化失败的事件会被静默丢弃，不会中断阶段本身的执行。
+func (e *NDJSONEventEmitter) EmitPhaseEvent(event PhaseEvent) {
+	raw, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	fmt.Fprintln(e.Writer, string(raw))
+}
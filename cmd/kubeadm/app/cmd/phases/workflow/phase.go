@@ -17,6 +17,8 @@ limitations under the License.
 package workflow
 
 import (
+	"context"
+
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
@@ -52,7 +54,9 @@ type Phase struct {
 
 	// Run 定义实现阶段操作的函数。
 	// 建议执行类型断言，例如使用golang type switch来验证RunData类型。
-	Run func(data RunData) error
+	// ctx由Runner按调度层级统一创建和取消：当同一层级中的某个阶段失败时，ctx会被取消，
+	// 其余仍在运行的阶段应当尽快以该失败而不是自身的部分结果退出。
+	Run func(ctx context.Context, data RunData) error
 
 	// RunIf 定义一个函数，该函数实现在执行阶段操作之前应检查的条件。
 	// 如果此函数返回nil，则始终执行阶段操作。
@@ -70,6 +74,33 @@ type Phase struct {
 	// ArgsValidator 定义用于验证此阶段的参数的位置参数函数
 	// 如果没有设置，阶段将采用顶级命令的参数。
 	ArgsValidator cobra.PositionalArgs
+
+	// FromPlugin为true表示该阶段并非由Runner的调用方通过AppendPhase直接注册，而是由
+	// Runner.DiscoverPlugins在$PATH中发现的外部kubeadm-phase-*插件二进制生成。
+	// 供Help与"kubeadm phase list"在展示阶段列表时区分内置阶段与插件阶段。
+	FromPlugin bool
+
+	// DependsOn列出了该阶段在开始执行前必须等待哪些其他阶段成功完成，取值为这些阶段在
+	// Runner管理的Workflow中解析后的完整路径名(与--skip-phases/--phase接受的值一致，
+	// 例如"certs/all")。与"子阶段隐式依赖父阶段完成"这条既有规则一起，构成
+	// Runner.prepareForExecution建立依赖图时使用的全部边。
+	DependsOn []string
+
+	// Parallel为true表示该阶段允许与依赖图中和它互不依赖、同样标记为Parallel的阶段并发执行，
+	// 最多并发Runner.Options.MaxParallelism个。为false(默认值)的阶段总是单独占据一个调度层级，
+	// 即保留引入DAG调度之前完全线性、可预测的执行顺序。
+	Parallel bool
+
+	// DryRunSafe为true表示该阶段的RunIf只读取状态、不产生副作用，因此在Runner.Options.DryRun下
+	// 调用它来判断该阶段本应是否会执行是安全的。为false(默认值)的阶段在试运行下不会被调用RunIf，
+	// Runner会直接把它当作"会执行"纳入执行计划上报，因为没有把握在不改变集群状态的前提下求值
+	// 它的运行条件。无论DryRunSafe取值如何，试运行下都绝不会调用该阶段的Run。
+	DryRunSafe bool
+
+	// NonResumable为true表示即便--state-dir下的Journal已经把该阶段记录为succeeded，
+	// --resume也必须无条件重新执行它，例如preflight这类每次执行都必须重新校验当前环境的阶段——
+	// 上一次成功并不能保证校验过的前提条件在这一次续跑时仍然成立。
+	NonResumable bool
 }
 
 // AppendPhase 将给定阶段添加到嵌套的有序阶段序列中。
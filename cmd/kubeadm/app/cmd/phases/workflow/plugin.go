@@ -0,0 +1,217 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	errorsutil "k8s.io/apimachinery/pkg/util/errors"
+	kubeadmutil "k8s.io/kubernetes/cmd/kubeadm/app/util"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+	"k8s.io/klog/v2"
+)
+
+// describePluginTimeout限制"<plugin> --describe"握手这一步可以运行多久: 这个候选二进制只是
+// 匹配了文件名前缀, 还没有经过任何信任校验, 一个卡死(或者恶意设计为永不退出)的二进制不应该
+// 拖住整个阶段发现过程, 更不应该让调用方(见DiscoverPlugins)永久挂起。
+const describePluginTimeout = 5 * time.Second
+
+// ValidPluginFilenamePrefixes 是Runner.DiscoverPlugins在$PATH中查找外部阶段插件二进制时
+// 接受的文件名前缀列表, 仿照kubectl插件处理器里的同名概念。调用方可以替换这个变量以支持额外的
+// 前缀(例如为测试注入一个互不冲突的前缀)。
+var ValidPluginFilenamePrefixes = []string{"kubeadm-phase"}
+
+// PluginDescribe是外部阶段插件在收到"--describe"参数时必须向标准输出打印的JSON。
+// Runner在注册阶段时读取这份描述信息，以便插件阶段在帮助文本、阶段列表和标志继承方面
+// 与内置阶段没有区别。
+type PluginDescribe struct {
+	// Short是插件阶段的简短描述，用途与Phase.Short相同。
+	Short string `json:"short"`
+	// Long是插件阶段的详细描述，用途与Phase.Long相同。
+	Long string `json:"long,omitempty"`
+	// Aliases是插件阶段的别名列表，用途与Phase.Aliases相同。
+	Aliases []string `json:"aliases,omitempty"`
+	// InheritFlags列出了插件希望从宿主命令继承的标志名称，用途与Phase.InheritFlags相同。
+	InheritFlags []string `json:"inheritFlags,omitempty"`
+}
+
+// pluginPreflightError把一个插件进程的预检类失败(退出码2)包装为一个实现了kubeadmutil内部
+// preflightError标记接口的错误, 使其最终经由kubeadmutil.CheckErr映射回PreFlightExitCode,
+// 就像内置的预检阶段一样。
+type pluginPreflightError struct {
+	err error
+}
+
+func (e *pluginPreflightError) Error() string { return e.err.Error() }
+
+// Preflight让pluginPreflightError满足kubeadmutil.CheckErr内部识别的preflightError接口。
+func (e *pluginPreflightError) Preflight() bool { return true }
+
+// DiscoverPlugins在$PATH中扫描文件名匹配prefixes之一(形如"<prefix>-<phase名称>")、且具有可
+// 执行权限的二进制, 并把每一个都注册为Runner管理的Workflow中的一个新阶段。每个候选二进制都会
+// 先以"--describe"参数被调用一次, 用它打印到标准输出的PluginDescribe JSON来填充该阶段的
+// Short/Long/Aliases/InheritFlags；无法成功完成这次握手的候选会被跳过并记录一条警告, 不会
+// 中断其余插件的发现。重名(按"<phase名称>"去重)时，$PATH中先出现的目录优先。
+func (e *Runner) DiscoverPlugins(prefixes []string) {
+	seen := map[string]bool{}
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			phaseName, ok := matchPluginFilename(entry.Name(), prefixes)
+			if !ok || phaseName == "" || seen[phaseName] {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil || info.IsDir() || info.Mode()&0111 == 0 {
+				continue
+			}
+
+			pluginPath := filepath.Join(dir, entry.Name())
+			describe, err := describePlugin(pluginPath)
+			if err != nil {
+				klog.Warningf("[插件] 无法获取 %s 的描述信息，已跳过: %v", pluginPath, err)
+				continue
+			}
+
+			seen[phaseName] = true
+			e.AppendPhase(Phase{
+				Name:         phaseName,
+				Short:        describe.Short,
+				Long:         describe.Long,
+				Aliases:      describe.Aliases,
+				InheritFlags: describe.InheritFlags,
+				FromPlugin:   true,
+				Run:          e.newPluginRunFunc(pluginPath),
+			})
+		}
+	}
+}
+
+// matchPluginFilename检查name是否匹配"<prefix>-<phase名称>"的形式(prefix取自prefixes之一)，
+// 是则返回phase名称部分。
+func matchPluginFilename(name string, prefixes []string) (string, bool) {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(name, prefix+"-") {
+			return strings.TrimPrefix(name, prefix+"-"), true
+		}
+	}
+	return "", false
+}
+
+// describePlugin执行pluginPath --describe, 并把它打印到标准输出的内容解析为PluginDescribe。
+// 执行被绑定在describePluginTimeout之内, 超时会被当作一次失败的握手处理(调用方只是跳过这个
+// 候选并记录警告, 不会中断发现过程的其余部分)。
+func describePlugin(pluginPath string) (*PluginDescribe, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), describePluginTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, pluginPath, "--describe").Output()
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, errors.Errorf("执行 %s --describe 超过%s未返回，已放弃", pluginPath, describePluginTimeout)
+		}
+		return nil, errors.Wrapf(err, "执行 %s --describe 失败", pluginPath)
+	}
+
+	var describe PluginDescribe
+	if err := json.Unmarshal(out, &describe); err != nil {
+		return nil, errors.Wrapf(err, "无法解析 %s --describe 输出的JSON", pluginPath)
+	}
+	return &describe, nil
+}
+
+// newPluginRunFunc返回一个Phase.Run实现, 它按如下协议把阶段的执行委托给外部插件二进制:
+//   - 当前阶段的RunData以JSON的形式序列化后通过标准输入传给插件进程；
+//   - 触发本次执行的命令(e.runCmd，即顶层命令或该阶段生成的子命令，取决于调用方式)中已解析的、
+//     发生了变化的标志以"--名称=值"的形式转发为插件进程的命令行参数；
+//   - 插件进程的标准输出/标准错误直接透传给kubeadm自身的标准输出/标准错误；
+//   - 插件进程的退出码按照kubeadmutil.CheckErr的分类折返回Go错误: 2 -> 预检类错误,
+//     3 -> 校验类错误(通过errorsutil.Aggregate表达)，其余非零退出码 -> 普通错误。
+//
+// ctx由Runner的调度层级统一管理: 一旦同一层级中的其他阶段失败导致ctx被取消，插件子进程也会
+// 随之收到终止信号，不会成为孤儿进程继续运行。
+func (e *Runner) newPluginRunFunc(pluginPath string) func(ctx context.Context, data RunData) error {
+	return func(ctx context.Context, data RunData) error {
+		payload, err := json.Marshal(data)
+		if err != nil {
+			return errors.Wrapf(err, "无法把RunData序列化为JSON以传递给插件 %s", pluginPath)
+		}
+
+		args := pluginForwardedArgs(e)
+
+		cmd := exec.CommandContext(ctx, pluginPath, args...)
+		cmd.Stdin = bytes.NewReader(payload)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+
+		if err := cmd.Run(); err != nil {
+			return mapPluginExitError(pluginPath, err)
+		}
+		return nil
+	}
+}
+
+// pluginForwardedArgs从触发本次执行的命令中收集已经被用户显式设置过的标志, 转换为插件进程的
+// 命令行参数。e.runCmd为nil(Runner尚未绑定到任何命令，例如在未经BindToCommand的单元测试中直接
+// 调用Run)时返回一个空切片。
+func pluginForwardedArgs(e *Runner) []string {
+	args := []string{}
+	if e.runCmd == nil {
+		return args
+	}
+	e.runCmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if f.Changed {
+			args = append(args, fmt.Sprintf("--%s=%s", f.Name, f.Value.String()))
+		}
+	})
+	return args
+}
+
+// mapPluginExitError把一次插件进程失败的执行转换为Go错误, 按其退出码折返回kubeadmutil.CheckErr
+// 能够识别的错误分类，使得插件阶段失败时kubeadm报告的退出码与内置阶段保持一致。
+func mapPluginExitError(pluginPath string, err error) error {
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok {
+		return errors.Wrapf(err, "无法执行插件 %s", pluginPath)
+	}
+
+	baseErr := errors.Errorf("插件 %s 以退出码 %d 结束执行", pluginPath, exitErr.ExitCode())
+	switch exitErr.ExitCode() {
+	case kubeadmutil.PreFlightExitCode:
+		return &pluginPreflightError{err: baseErr}
+	case kubeadmutil.ValidationExitCode:
+		return errorsutil.NewAggregate([]error{baseErr})
+	default:
+		return baseErr
+	}
+}
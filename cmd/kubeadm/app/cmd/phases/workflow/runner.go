@@ -17,12 +17,19 @@ limitations under the License.
 package workflow
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"golang.org/x/sync/errgroup"
+
+	"k8s.io/kubernetes/cmd/kubeadm/app/util/initjournal"
 )
 
 // phaseSeparator 定义连接嵌套阶段名称时要使用的分隔符
@@ -35,6 +42,33 @@ type RunnerOptions struct {
 
 	// SkipPhases 定义要通过执行排除的阶段列表（如果为空, 则为无）。
 	SkipPhases []string
+
+	// StateDir定义持久化各阶段执行状态(Journal)的目录。为空表示不启用Journal持久化。
+	StateDir string
+
+	// Resume为true表示加载StateDir下已有的Journal, 校验其记录的配置指纹与当前运行时数据一致后,
+	// 跳过其中已经标记为succeeded的阶段。
+	Resume bool
+
+	// RestartFrom指定一个阶段的完整路径名(与SkipPhases接受的值一致): 该阶段及其之后的所有阶段都会
+	// 被强制重新执行, 忽略它们在Journal中记录的状态; 该阶段之前的阶段则无条件跳过。为空表示不启用。
+	RestartFrom string
+
+	// MaxParallelism限制同一个调度层级内可以并发执行的阶段数量上限。零值等价于1，
+	// 即保持引入DAG调度之前完全线性的执行语义，这也是为了向后兼容而选择的默认值。
+	MaxParallelism int
+
+	// DryRun为true时，Run不会调用任何阶段的Run方法，只对标记为DryRunSafe的阶段调用其RunIf
+	// 判断是否会被执行，并通过EventEmitter把这份执行计划报告出去，不触碰集群状态。
+	DryRun bool
+}
+
+// ConfigFingerprinter可以被RunData可选地实现, 为Journal持久化提供一份配置内容指纹。
+// Runner在启用了Journal持久化时, 用它在--resume时校验--state-dir中记录的Journal是否仍然对应
+// 当前这一份运行时配置。没有实现该接口的RunData不会启用指纹校验, 但仍然可以使用Journal持久化
+// 与--restart-from。
+type ConfigFingerprinter interface {
+	ConfigFingerprint() (string, error)
 }
 
 // RunData 定义工作流中包括的所有阶段（即任何类型）之间共享的数据
@@ -62,10 +96,18 @@ type Runner struct {
 
 	// phaseRunners是Runner内部状态的一部分，它为组成Workflow的阶段提供一个包装列表，其中包含支持阶段执行的上下文信息。
 	phaseRunners []*phaseRunner
+
+	// schedule是Runner内部状态的一部分，由prepareForExecution根据phaseRunners的隐式父子关系
+	// 与各阶段显式声明的DependsOn构建而成：每个元素是一个可以互不阻塞地并发执行的调度层级，
+	// 层级之间保持严格的先后顺序。
+	schedule [][]*phaseRunner
+
+	// pluginsDiscovered保证DiscoverPlugins只会在Workflow真正被执行或其阶段列表被查询时才触发，
+	// 并且只执行一次，而不是在BindToCommand挂载子命令时就无条件触发——见ensurePluginsDiscovered。
+	pluginsDiscovered sync.Once
 }
 
 // phaseRunner 为一个阶段提供了一个包装器，添加了一组由Runner管理的Workflow派生的上下文信息。
-// TODO: 如果我们决定变得更复杂，我们可以用定义良好的DAG或Tree库替换这种类型。
 type phaseRunner struct {
 	// 阶段提供对阶段实施的访问
 	Phase
@@ -105,6 +147,7 @@ func (e *Runner) computePhaseRunFlags() (map[string]bool, error) {
 	// 初始化支持数据结构
 	phaseRunFlags := map[string]bool{}
 	phaseHierarchy := map[string][]string{}
+	dependsOn := map[string][]string{}
 	_ = e.visitAll(func(p *phaseRunner) error {
 		// 假设所有阶段都应该运行，初始化phaseRunFlags。
 		phaseRunFlags[p.generatedName] = true
@@ -112,6 +155,9 @@ func (e *Runner) computePhaseRunFlags() (map[string]bool, error) {
 		// 为当前的阶段初始化 phaseHierarchy (取决于当前阶段的阶段列表)
 		phaseHierarchy[p.generatedName] = []string{}
 
+		// 记录当前阶段显式声明的DependsOn，供下面拉入上游依赖/连带跳过下游依赖使用。
+		dependsOn[p.generatedName] = append([]string{}, p.DependsOn...)
+
 		// 将当前阶段注册为其自身父层次结构的一部分
 		parent := p.parent
 		for parent != nil {
@@ -121,6 +167,15 @@ func (e *Runner) computePhaseRunFlags() (map[string]bool, error) {
 		return nil
 	})
 
+	// dependents是dependsOn的反向映射：dependents[x]列出了所有显式依赖x的阶段，
+	// 用于在x被跳过时连带跳过它们。
+	dependents := map[string][]string{}
+	for name, deps := range dependsOn {
+		for _, d := range deps {
+			dependents[d] = append(dependents[d], name)
+		}
+	}
+
 	// 如果指定了过滤器选项，则将所有phaseRunFlags设置为false，但过滤器中包含的阶段及其嵌套阶段的层次结构除外。
 	if len(e.Options.FilterPhases) > 0 {
 		for i := range phaseRunFlags {
@@ -134,6 +189,9 @@ func (e *Runner) computePhaseRunFlags() (map[string]bool, error) {
 			for _, c := range phaseHierarchy[f] {
 				phaseRunFlags[c] = true
 			}
+			// --phase选中的阶段即便不是彼此的嵌套父级/子级，只要声明了依赖关系，
+			// 这些上游依赖也必须被隐式地一并启用，否则该阶段会在必要的前置阶段缺失的情况下执行。
+			enablePhaseDependencies(f, dependsOn, phaseRunFlags)
 		}
 	}
 
@@ -146,11 +204,68 @@ func (e *Runner) computePhaseRunFlags() (map[string]bool, error) {
 		for _, c := range phaseHierarchy[f] {
 			phaseRunFlags[c] = false
 		}
+		// 跳过一个阶段时，所有(直接或传递)依赖它的阶段也必须连带跳过，而不仅仅是它的嵌套子阶段，
+		// 否则它们会在一个必要的上游阶段缺失的情况下继续执行。
+		disablePhaseDependents(f, dependents, phaseRunFlags)
 	}
 
 	return phaseRunFlags, nil
 }
 
+// enablePhaseDependencies递归地把name显式声明依赖(DependsOn)的全部阶段标记为需要执行。
+func enablePhaseDependencies(name string, dependsOn map[string][]string, flags map[string]bool) {
+	for _, d := range dependsOn[name] {
+		if !flags[d] {
+			flags[d] = true
+			enablePhaseDependencies(d, dependsOn, flags)
+		}
+	}
+}
+
+// disablePhaseDependents递归地把所有(直接或传递)依赖于name的阶段标记为跳过。
+func disablePhaseDependents(name string, dependents map[string][]string, flags map[string]bool) {
+	for _, d := range dependents[name] {
+		if flags[d] {
+			flags[d] = false
+			disablePhaseDependents(d, dependents, flags)
+		}
+	}
+}
+
+// PhaseStatus描述Runner管理的Workflow中某一个阶段最终是被执行还是被跳过。
+type PhaseStatus struct {
+	// Name是阶段在Workflow中的完整路径名(与--skip-phases/--phase接受的值一致)。
+	Name string
+	// Skipped为true表示该阶段因为Options.FilterPhases/Options.SkipPhases而没有运行。
+	Skipped bool
+}
+
+// PhaseStatuses按照当前的Options.FilterPhases/Options.SkipPhases计算出Workflow中每一个非隐藏
+// 阶段最终的执行状态，而不需要真的调用Run。主要供调用方在Run成功完成后，把“哪些阶段被跳过”
+// 一并写入结构化的命令输出中。
+func (e *Runner) PhaseStatuses() ([]PhaseStatus, error) {
+	if err := e.prepareForExecution(); err != nil {
+		return nil, err
+	}
+	phaseRunFlags, err := e.computePhaseRunFlags()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := []PhaseStatus{}
+	_ = e.visitAll(func(p *phaseRunner) error {
+		if p.Hidden {
+			return nil
+		}
+		statuses = append(statuses, PhaseStatus{
+			Name:    p.generatedName,
+			Skipped: !phaseRunFlags[p.generatedName],
+		})
+		return nil
+	})
+	return statuses, nil
+}
+
 // SetDataInitializer 允许设置初始化Workflow中所有阶段共享的运行时数据的函数。
 // 该方法将在输入中接收触发运行程序的cmd（仅当Runner是BindToCommand时）
 func (e *Runner) SetDataInitializer(builder func(cmd *cobra.Command, args []string) (RunData, error)) {
@@ -174,8 +289,17 @@ func (e *Runner) InitData(args []string) (RunData, error) {
 }
 
 // Run kubeadm可组合的kubeadm Workflow。
+//
+// 阶段按Runner.schedule中的调度层级依次推进：同一层级内的阶段彼此没有依赖关系，会并发执行，
+// 最多并发Options.MaxParallelism个；一旦该层级中的某个阶段失败，其余仍在运行的阶段会通过
+// 共享的context.Context尽快感知到取消信号，Run会在当前层级完全退出后立即返回这一错误，
+// 不再进入下一个调度层级。
 func (e *Runner) Run(args []string) error {
-	e.prepareForExecution()
+	e.ensurePluginsDiscovered()
+
+	if err := e.prepareForExecution(); err != nil {
+		return err
+	}
 
 	// 根据RunnerOptions确定应该运行哪个阶段
 	phaseRunFlags, err := e.computePhaseRunFlags()
@@ -183,52 +307,241 @@ func (e *Runner) Run(args []string) error {
 		return err
 	}
 
+	if e.Options.RestartFrom != "" {
+		if _, ok := phaseRunFlags[e.Options.RestartFrom]; !ok {
+			return errors.Errorf("无效的阶段名称: %s", e.Options.RestartFrom)
+		}
+	}
+
 	// 构建Runner数据
 	var data RunData
 	if data, err = e.InitData(args); err != nil {
 		return err
 	}
 
-	err = e.visitAll(func(p *phaseRunner) error {
-		// 如果不应运行该阶段，请跳过该阶段。
-		if run, ok := phaseRunFlags[p.generatedName]; !run || !ok {
-			return nil
-		}
+	// 如果启用了Journal持久化，加载（在--resume/--restart-from下）或创建一份新的Journal
+	journal, err := e.loadOrCreateJournal(data)
+	if err != nil {
+		return err
+	}
 
-		// 如果仅用于创建特殊子命令的阶段被错误地分配了运行方法，则会出现错误
-		if p.RunAllSiblings && (p.RunIf != nil || p.Run != nil) {
-			return errors.Errorf("标记为RunAllSides的阶段不能有运行函数 %s", p.generatedName)
-		}
+	// 如果data实现了EventEmitterProvider，取出其EventEmitter，以便在执行阶段期间发出结构化事件。
+	var emitter EventEmitter
+	if p, ok := data.(EventEmitterProvider); ok {
+		emitter = p.EventEmitter()
+	}
+
+	// restartFromReached在没有指定--restart-from时从一开始就视为已到达，从而不影响正常的执行顺序。
+	restartFromReached := e.Options.RestartFrom == ""
 
-		// 如果阶段定义了在执行阶段操作之前要检查的条件。
-		if p.RunIf != nil {
-			// Check the condition and returns if the condition isn't satisfied (or fails)
-			ok, err := p.RunIf(data)
-			if err != nil {
-				return errors.Wrapf(err, "阶段的错误执行运行条件 %s", p.generatedName)
+	maxParallelism := e.Options.MaxParallelism
+	if maxParallelism < 1 {
+		maxParallelism = 1
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var journalMu sync.Mutex
+
+	for _, level := range e.schedule {
+		// 在启动任何goroutine之前，先按调度层级内的原始顺序依次决定哪些阶段真的需要运行，
+		// 因为restartFromReached与Journal的resume判断都依赖于这份决定是按顺序、确定性地做出的。
+		var toRun []*phaseRunner
+		for _, p := range level {
+			// 如果不应运行该阶段，请跳过该阶段。
+			if run, ok := phaseRunFlags[p.generatedName]; !run || !ok {
+				continue
 			}
 
-			if !ok {
-				return nil
+			// 如果仅用于创建特殊子命令的阶段被错误地分配了运行方法，则会出现错误
+			if p.RunAllSiblings && (p.RunIf != nil || p.Run != nil) {
+				return errors.Errorf("标记为RunAllSides的阶段不能有运行函数 %s", p.generatedName)
 			}
-		}
 
-		// 运行阶段操作（如果已定义）
-		if p.Run != nil {
-			if err := p.Run(data); err != nil {
-				return errors.Wrapf(err, "错误执行阶段 %s", p.generatedName)
+			if e.Options.RestartFrom != "" {
+				// --restart-from之前的阶段无条件跳过（假定它们在上一次尝试中已经完成）；
+				// --restart-from自身及其之后的阶段都会被强制重新执行，不再查询Journal中记录的状态。
+				if !restartFromReached {
+					if p.generatedName != e.Options.RestartFrom {
+						continue
+					}
+					restartFromReached = true
+				}
+			} else if journal != nil && !p.NonResumable && journal.Succeeded(p.generatedName) {
+				// --resume下，Journal中已经记录为succeeded的阶段直接跳过；标记为NonResumable的
+				// 阶段(例如preflight)即便已经succeeded也必须重新执行。
+				continue
 			}
+
+			toRun = append(toRun, p)
+		}
+
+		if len(toRun) == 0 {
+			continue
 		}
 
+		sem := make(chan struct{}, maxParallelism)
+		g, levelCtx := errgroup.WithContext(ctx)
+		for _, p := range toRun {
+			p := p
+			g.Go(func() error {
+				sem <- struct{}{}
+				defer func() { <-sem }()
+				return e.runOnePhase(levelCtx, p, data, journal, emitter, &journalMu)
+			})
+		}
+
+		if err := g.Wait(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// runOnePhase执行单个阶段的RunIf条件检查、Run动作以及围绕它的Journal记录与事件发出；
+// 如果Options.DryRun为true，转而调用planOnePhase，绝不触碰集群状态。
+// Run按调度层级并发执行阶段时，同一层级中的每个阶段都在各自的goroutine里调用这个方法；
+// journalMu保护对同一份Journal的并发写入，因为initjournal.Journal本身不是为并发访问设计的。
+func (e *Runner) runOnePhase(ctx context.Context, p *phaseRunner, data RunData, journal *initjournal.Journal, emitter EventEmitter, journalMu *sync.Mutex) error {
+	// 没有定义运行函数的阶段（例如仅用于分组嵌套子阶段的容器阶段）不需要进一步处理。
+	if p.Run == nil {
 		return nil
-	})
+	}
+
+	if e.Options.DryRun {
+		return e.planOnePhase(p, data, emitter)
+	}
+
+	// 如果阶段定义了在执行阶段操作之前要检查的条件。
+	if p.RunIf != nil {
+		// Check the condition and returns if the condition isn't satisfied (or fails)
+		ok, err := p.RunIf(data)
+		if err != nil {
+			return errors.Wrapf(err, "阶段的错误执行运行条件 %s", p.generatedName)
+		}
+
+		if !ok {
+			return nil
+		}
+	}
+
+	startedAt := time.Now()
+	if emitter != nil {
+		emitter.EmitPhaseEvent(newPhaseEvent(p, PhaseEventStatusRunning, startedAt, 0, nil))
+	}
+
+	if journal != nil {
+		journalMu.Lock()
+		err := journal.MarkRunning(e.Options.StateDir, p.generatedName)
+		journalMu.Unlock()
+		if err != nil {
+			return errors.Wrapf(err, "无法更新阶段 %s 的Journal记录", p.generatedName)
+		}
+	}
+
+	if err := p.Run(ctx, data); err != nil {
+		if journal != nil {
+			// 即便写入失败状态本身出错，也优先把原始的阶段错误返回给调用方。
+			journalMu.Lock()
+			_ = journal.MarkFailed(e.Options.StateDir, p.generatedName, err)
+			journalMu.Unlock()
+		}
+		if emitter != nil {
+			emitter.EmitPhaseEvent(newPhaseEvent(p, PhaseEventStatusFailed, startedAt, time.Since(startedAt), err))
+		}
+		return errors.Wrapf(err, "错误执行阶段 %s", p.generatedName)
+	}
+
+	if journal != nil {
+		journalMu.Lock()
+		err := journal.MarkSucceeded(e.Options.StateDir, p.generatedName)
+		journalMu.Unlock()
+		if err != nil {
+			return errors.Wrapf(err, "无法更新阶段 %s 的Journal记录", p.generatedName)
+		}
+	}
+
+	if emitter != nil {
+		emitter.EmitPhaseEvent(newPhaseEvent(p, PhaseEventStatusSucceeded, startedAt, time.Since(startedAt), nil))
+	}
 
-	return err
+	return nil
+}
+
+// planOnePhase在Options.DryRun下代替runOnePhase的主体逻辑：只有当阶段被标记为DryRunSafe时，
+// 才会调用其RunIf来判断该阶段本应是否会执行(未标记的阶段一律视为会执行，因为没有把握在不
+// 改变集群状态的前提下求值它的运行条件)；无论结果如何，都绝不调用阶段的Run，也不触碰Journal，
+// 而是把这份判断通过emitter(若存在)上报为一条skipped或planned事件，供--output=json等
+// 自动化场景据此得到一份执行计划。
+func (e *Runner) planOnePhase(p *phaseRunner, data RunData, emitter EventEmitter) error {
+	wouldRun := true
+	if p.DryRunSafe && p.RunIf != nil {
+		ok, err := p.RunIf(data)
+		if err != nil {
+			return errors.Wrapf(err, "阶段的错误执行运行条件 %s", p.generatedName)
+		}
+		wouldRun = ok
+	}
+
+	status := PhaseEventStatusPlanned
+	if !wouldRun {
+		status = PhaseEventStatusSkipped
+	}
+
+	now := time.Now()
+	if emitter != nil {
+		emitter.EmitPhaseEvent(newPhaseEvent(p, status, now, 0, nil))
+	} else {
+		verb := "将会执行"
+		if !wouldRun {
+			verb = "将会被跳过"
+		}
+		fmt.Printf("[试运行] 阶段 %s %s\n", p.generatedName, verb)
+	}
+
+	return nil
+}
+
+// loadOrCreateJournal在Options.StateDir非空时返回一份可用的Journal：若Options.Resume或
+// Options.RestartFrom要求延续上一次执行且--state-dir下已经存在Journal文件，则加载它，并在
+// data实现了ConfigFingerprinter时校验配置指纹是否与上一次执行一致；否则返回一份绑定了当前
+// 配置指纹的全新Journal。Options.StateDir为空时返回(nil, nil)，表示不启用Journal持久化。
+func (e *Runner) loadOrCreateJournal(data RunData) (*initjournal.Journal, error) {
+	if e.Options.StateDir == "" {
+		return nil, nil
+	}
+
+	fingerprint := ""
+	if fp, ok := data.(ConfigFingerprinter); ok {
+		var err error
+		if fingerprint, err = fp.ConfigFingerprint(); err != nil {
+			return nil, errors.Wrap(err, "无法计算配置指纹")
+		}
+	}
+
+	if e.Options.Resume || e.Options.RestartFrom != "" {
+		existing, err := initjournal.Load(e.Options.StateDir)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			if fingerprint != "" && existing.ConfigHash != fingerprint {
+				return nil, errors.New("无法恢复执行: 当前配置与--state-dir中记录的配置指纹不一致，请勿在--resume/--restart-from时修改配置")
+			}
+			return existing, nil
+		}
+	}
+
+	return initjournal.New(fingerprint), nil
 }
 
 // Help 返回包含Workflow中包含的阶段列表的文本。
 func (e *Runner) Help(cmdUse string) string {
-	e.prepareForExecution()
+	// Help只读取阶段的展示信息(层级、名称、简短描述)，这些都只取决于phaseRunners的构建，
+	// 与DAG调度无关，因此这里忽略prepareForExecution可能返回的依赖图错误。
+	_ = e.prepareForExecution()
 
 	// 计算每个阶段使用行的最大长度
 	maxLength := 0
@@ -281,18 +594,42 @@ func (e *Runner) SetAdditionalFlags(fn func(*pflag.FlagSet)) {
 	fn(e.cmdAdditionalFlags)
 }
 
+// ensurePluginsDiscovered触发一次(且只有一次)DiscoverPlugins，供Run与"phase list"在真正需要
+// 完整阶段列表时调用，取代此前在BindToCommand挂载子命令时就无条件执行的扫描。
+//
+// kubeadm在启动时会为init/join/reset都构建一份完整的cobra子命令树(用于生成顶层帮助文本)，
+// 不论用户实际输入的是哪个子命令；如果DiscoverPlugins在那一步(即BindToCommand)就执行，哪怕
+// 用户实际运行的是`kubeadm version`这类完全不涉及init/join/reset的命令，也会在每次kubeadm
+// 进程启动时扫描并执行$PATH上每一个匹配"kubeadm-phase-*"前缀的二进制——一个卡死或被恶意放置的
+// 候选二进制就能拖慢甚至攻陷整个CLI。把发现推迟到这里，使其只在Workflow真正被执行(Run)或阶段
+// 列表被查询("phase list")时才触发，两者都只会发生在用户确实选择了init/join/reset子命令之后。
+//
+// 代价: 由BindToCommand在构建时生成的单个阶段子命令(`kubeadm init phase <插件名>`这种直接按名
+// 调用插件阶段的形式)和`--help`文本只反映截至那一刻已经通过AppendPhase注册的内置阶段——尚未被
+// 发现的插件阶段不会单独出现在这两处，直到Run或"phase list"实际执行过一次。
+func (e *Runner) ensurePluginsDiscovered() {
+	e.pluginsDiscovered.Do(func() {
+		e.DiscoverPlugins(ValidPluginFilenamePrefixes)
+	})
+}
+
 // BindToCommand 通过更改命令帮助、添加阶段相关标志和添加阶段子命令，将Runner绑定到cobra命令
 // 请注意，一旦所有阶段都添加到Runner中，就需要执行此命令。
 func (e *Runner) BindToCommand(cmd *cobra.Command) {
 	// 跟踪触发Runner的命令
 	e.runCmd = cmd
 
+	// 注意: 这里不再调用DiscoverPlugins——见ensurePluginsDiscovered，发现被推迟到Run/"phase list"
+	// 真正执行的时候，这样`kubeadm version`这类不涉及init/join/reset的命令不会触发它。
+
 	// 如果没有添加阶段，请提前返回
 	if len(e.Phases) == 0 {
 		return
 	}
 
-	e.prepareForExecution()
+	// BindToCommand只是用phaseRunners生成子命令和帮助文本，真正的依赖图校验在Run执行时再次
+	// 发生并会返回给调用方；这里忽略错误以保持该方法原有的无返回值签名。
+	_ = e.prepareForExecution()
 
 	// 添加阶段的子命令
 	phaseCommand := &cobra.Command{
@@ -302,6 +639,10 @@ func (e *Runner) BindToCommand(cmd *cobra.Command) {
 
 	cmd.AddCommand(phaseCommand)
 
+	// 添加"list"子命令，列出此Workflow当前全部的内置阶段与通过插件注册的阶段
+	// (包括它们各自嵌套的子阶段)，供操作者在不实际执行的情况下确认插件是否被正确发现。
+	phaseCommand.AddCommand(e.newPhaseListCommand())
+
 	// 生成用于调用单个阶段的所有嵌套子命令
 	subcommands := map[string]*cobra.Command{}
 	_ = e.visitAll(func(p *phaseRunner) error {
@@ -387,6 +728,34 @@ func (e *Runner) BindToCommand(cmd *cobra.Command) {
 	cmd.Flags().StringSliceVar(&e.Options.SkipPhases, "skip-phases", nil, "要跳过的阶段列表")
 }
 
+// newPhaseListCommand构建"kubeadm ... phase list"子命令，按执行顺序打印Workflow中全部的
+// 非隐藏阶段，对通过插件发现的阶段额外标注"(plugin)"，便于操作者区分内置阶段与外部插件。
+func (e *Runner) newPhaseListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "列出此Workflow全部的内置阶段与通过插件注册的阶段",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			e.ensurePluginsDiscovered()
+
+			if err := e.prepareForExecution(); err != nil {
+				return err
+			}
+			_ = e.visitAll(func(p *phaseRunner) error {
+				if p.Hidden || p.RunAllSiblings {
+					return nil
+				}
+				line := p.generatedName
+				if p.FromPlugin {
+					line += " (plugin)"
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), line)
+				return nil
+			})
+			return nil
+		},
+	}
+}
+
 func inheritsFlags(sourceFlags, targetFlags *pflag.FlagSet, cmdFlags []string) {
 	// 如果未定义要从父命令继承的标志列表，则不会添加任何标志
 	if cmdFlags == nil {
@@ -414,8 +783,9 @@ func (e *Runner) visitAll(fn func(*phaseRunner) error) error {
 	return nil
 }
 
-// prepareForExecution 初始化Runner的内部状态（phaseRunner列表）。
-func (e *Runner) prepareForExecution() {
+// prepareForExecution 初始化Runner的内部状态（phaseRunner列表），并重新计算DAG调度
+// （Runner.schedule）。当某个阶段的DependsOn引用了不存在的阶段、或依赖图中存在环时返回错误。
+func (e *Runner) prepareForExecution() error {
 	e.phaseRunners = []*phaseRunner{}
 	var parentRunner *phaseRunner
 	for _, phase := range e.Phases {
@@ -427,6 +797,125 @@ func (e *Runner) prepareForExecution() {
 		// 将阶段添加到执行列表中
 		addPhaseRunner(e, parentRunner, phase)
 	}
+	return e.buildSchedule()
+}
+
+// buildSchedule根据phaseRunners构建一份依赖图，并把它拓扑排序为一组调度层级（Runner.schedule）：
+// 每个层级内的阶段互不依赖，可以并发执行；层级之间必须严格按顺序推进。依赖图的边来自三个来源：
+//   - 隐式的"子阶段依赖父阶段"规则；
+//   - 阶段自身通过Phase.DependsOn显式声明的依赖；
+//   - 隐式的"非并发阶段依赖其在原始声明顺序中的前一个阶段"规则，用于在没有任何阶段声明
+//     Phase.Parallel时，完全保留引入DAG调度之前的线性执行顺序。
+func (e *Runner) buildSchedule() error {
+	byName := map[string]*phaseRunner{}
+	for _, p := range e.phaseRunners {
+		byName[p.generatedName] = p
+	}
+
+	deps := map[string]map[string]bool{}
+	for i, p := range e.phaseRunners {
+		d := map[string]bool{}
+		if p.parent != nil {
+			d[p.parent.generatedName] = true
+		}
+		for _, name := range p.DependsOn {
+			if _, ok := byName[name]; !ok {
+				return errors.Errorf("阶段 %s 声明了依赖一个不存在的阶段 %q", p.generatedName, name)
+			}
+			d[name] = true
+		}
+		if i > 0 {
+			prev := e.phaseRunners[i-1]
+			if !(p.Parallel && prev.Parallel) {
+				d[prev.generatedName] = true
+			}
+		}
+		deps[p.generatedName] = d
+	}
+
+	if cycle := findDependencyCycle(e.phaseRunners, deps); len(cycle) > 0 {
+		return errors.Errorf("阶段依赖图中存在环: %s", strings.Join(cycle, " -> "))
+	}
+
+	done := map[string]bool{}
+	remaining := append([]*phaseRunner{}, e.phaseRunners...)
+	var schedule [][]*phaseRunner
+	for len(remaining) > 0 {
+		var level []*phaseRunner
+		var next []*phaseRunner
+		for _, p := range remaining {
+			ready := true
+			for dep := range deps[p.generatedName] {
+				if !done[dep] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				level = append(level, p)
+			} else {
+				next = append(next, p)
+			}
+		}
+		if len(level) == 0 {
+			// findDependencyCycle已经在上面拒绝了所有成环的依赖图，理论上不会走到这里。
+			return errors.New("无法计算阶段调度: 依赖图中存在无法消解的环")
+		}
+		for _, p := range level {
+			done[p.generatedName] = true
+		}
+		schedule = append(schedule, level)
+		remaining = next
+	}
+
+	e.schedule = schedule
+	return nil
+}
+
+// findDependencyCycle对deps描述的依赖图做深度优先遍历, 如果存在环则返回构成该环的阶段名称
+// (按依赖方向排列, 首尾相同)，否则返回nil。
+func findDependencyCycle(nodes []*phaseRunner, deps map[string]map[string]bool) []string {
+	const (
+		white = iota
+		gray
+		black
+	)
+	color := map[string]int{}
+	var path []string
+	var cycle []string
+
+	var visit func(name string) bool
+	visit = func(name string) bool {
+		color[name] = gray
+		path = append(path, name)
+		for dep := range deps[name] {
+			switch color[dep] {
+			case gray:
+				for i, n := range path {
+					if n == dep {
+						cycle = append(append([]string{}, path[i:]...), dep)
+						return true
+					}
+				}
+			case white:
+				if visit(dep) {
+					return true
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		color[name] = black
+		return false
+	}
+
+	for _, p := range nodes {
+		if color[p.generatedName] == white {
+			if visit(p.generatedName) {
+				return cycle
+			}
+		}
+	}
+	return nil
 }
 
 // addPhaseRunner 将给定阶段的phaseRunner添加到phaseRunner列表中
@@ -461,6 +950,28 @@ func addPhaseRunner(e *Runner, parentRunner *phaseRunner, phase Phase) {
 	}
 }
 
+// newPhaseEvent根据一个phaseRunner及其此刻的状态迁移构造对应的PhaseEvent。
+func newPhaseEvent(p *phaseRunner, status PhaseEventStatus, startedAt time.Time, duration time.Duration, err error) PhaseEvent {
+	event := PhaseEvent{
+		Phase:     p.selfPath[0],
+		Status:    status,
+		StartedAt: startedAt,
+	}
+	if len(p.selfPath) > 1 {
+		event.SubPhase = p.generatedName
+	}
+	if duration > 0 {
+		event.DurationMS = duration.Milliseconds()
+	}
+	if err != nil {
+		event.Err = err.Error()
+	}
+	if hostname, hostErr := os.Hostname(); hostErr == nil {
+		event.Node = hostname
+	}
+	return event
+}
+
 // cleanName 通过将名称小写并删除args描述符（如果有），使阶段名称适合runner帮助
 func cleanName(name string) string {
 	ret := strings.ToLower(name)
@@ -0,0 +1,234 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflow
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// scheduleNames把一份调度结果打平成各层级内阶段名称的切片，只用于测试失败时打印更易读的信息。
+func scheduleNames(schedule [][]*phaseRunner) [][]string {
+	names := make([][]string, len(schedule))
+	for i, level := range schedule {
+		for _, p := range level {
+			names[i] = append(names[i], p.generatedName)
+		}
+	}
+	return names
+}
+
+func TestBuildScheduleOrdersDependentPhasesIntoLaterLevels(t *testing.T) {
+	r := NewRunner()
+	r.AppendPhase(Phase{Name: "a", Parallel: true})
+	r.AppendPhase(Phase{Name: "b", Parallel: true, DependsOn: []string{"a"}})
+
+	if err := r.prepareForExecution(); err != nil {
+		t.Fatalf("prepareForExecution返回了意料之外的错误: %v", err)
+	}
+
+	if len(r.schedule) != 2 {
+		t.Fatalf("期望2个调度层级, 实际%d个: %v", len(r.schedule), scheduleNames(r.schedule))
+	}
+	if len(r.schedule[0]) != 1 || r.schedule[0][0].generatedName != "a" {
+		t.Fatalf("期望第一层级只包含a, 实际: %v", scheduleNames(r.schedule))
+	}
+	if len(r.schedule[1]) != 1 || r.schedule[1][0].generatedName != "b" {
+		t.Fatalf("期望第二层级只包含b, 实际: %v", scheduleNames(r.schedule))
+	}
+}
+
+func TestBuildScheduleDefaultsToFullySequential(t *testing.T) {
+	r := NewRunner()
+	r.AppendPhase(Phase{Name: "a"})
+	r.AppendPhase(Phase{Name: "b"})
+
+	if err := r.prepareForExecution(); err != nil {
+		t.Fatalf("prepareForExecution返回了意料之外的错误: %v", err)
+	}
+
+	// 两个阶段都没有声明Parallel, 因此即便彼此没有显式依赖, 也应当各自占据一个调度层级,
+	// 保留引入DAG调度之前完全线性的执行顺序。
+	if len(r.schedule) != 2 {
+		t.Fatalf("期望未声明Parallel的阶段各自占据一个调度层级, 实际: %v", scheduleNames(r.schedule))
+	}
+}
+
+func TestBuildScheduleGroupsParallelPhasesIntoSameLevel(t *testing.T) {
+	r := NewRunner()
+	r.AppendPhase(Phase{Name: "a", Parallel: true})
+	r.AppendPhase(Phase{Name: "b", Parallel: true})
+
+	if err := r.prepareForExecution(); err != nil {
+		t.Fatalf("prepareForExecution返回了意料之外的错误: %v", err)
+	}
+
+	if len(r.schedule) != 1 || len(r.schedule[0]) != 2 {
+		t.Fatalf("期望a、b被分到同一个调度层级, 实际: %v", scheduleNames(r.schedule))
+	}
+}
+
+func TestBuildScheduleRejectsDependencyCycle(t *testing.T) {
+	r := NewRunner()
+	r.AppendPhase(Phase{Name: "a", Parallel: true, DependsOn: []string{"b"}})
+	r.AppendPhase(Phase{Name: "b", Parallel: true, DependsOn: []string{"a"}})
+
+	err := r.prepareForExecution()
+	if err == nil {
+		t.Fatal("期望prepareForExecution在依赖图存在环时返回错误")
+	}
+	if !strings.Contains(err.Error(), "环") {
+		t.Fatalf("期望错误信息中提到依赖环, 实际: %v", err)
+	}
+}
+
+func TestBuildScheduleRejectsUnknownDependency(t *testing.T) {
+	r := NewRunner()
+	r.AppendPhase(Phase{Name: "a", DependsOn: []string{"no-such-phase"}})
+
+	if err := r.prepareForExecution(); err == nil {
+		t.Fatal("期望prepareForExecution在DependsOn引用不存在的阶段时返回错误")
+	}
+}
+
+func TestFindDependencyCycleDetectsCycle(t *testing.T) {
+	a := &phaseRunner{generatedName: "a"}
+	b := &phaseRunner{generatedName: "b"}
+	c := &phaseRunner{generatedName: "c"}
+	deps := map[string]map[string]bool{
+		"a": {"b": true},
+		"b": {"c": true},
+		"c": {"a": true},
+	}
+
+	cycle := findDependencyCycle([]*phaseRunner{a, b, c}, deps)
+	if len(cycle) == 0 {
+		t.Fatal("期望检测到依赖环")
+	}
+}
+
+func TestFindDependencyCycleNoCycle(t *testing.T) {
+	a := &phaseRunner{generatedName: "a"}
+	b := &phaseRunner{generatedName: "b"}
+	deps := map[string]map[string]bool{
+		"a": {},
+		"b": {"a": true},
+	}
+
+	if cycle := findDependencyCycle([]*phaseRunner{a, b}, deps); len(cycle) != 0 {
+		t.Fatalf("期望没有环, 实际: %v", cycle)
+	}
+}
+
+// TestRunExecutesParallelLevelConcurrently验证同一个调度层级内标记为Parallel的阶段确实并发
+// 执行, 而不是被buildSchedule意外拆回串行: 两个阶段都先通过started上报自己已经进入Run,
+// 再阻塞等待release被关闭才返回。如果调度把它们放进了两个串行层级, 第二个阶段要等第一个阶段
+// 的Run返回(即release被关闭)之后才会开始执行、上报started, 下面等待两条started消息的循环
+// 会一直阻塞到超时。
+func TestRunExecutesParallelLevelConcurrently(t *testing.T) {
+	started := make(chan string, 2)
+	release := make(chan struct{})
+
+	r := NewRunner()
+	r.Options.MaxParallelism = 2
+	r.SetDataInitializer(func(*cobra.Command, []string) (RunData, error) {
+		return struct{}{}, nil
+	})
+	r.AppendPhase(Phase{
+		Name:     "a",
+		Parallel: true,
+		Run: func(ctx context.Context, data RunData) error {
+			started <- "a"
+			<-release
+			return nil
+		},
+	})
+	r.AppendPhase(Phase{
+		Name:     "b",
+		Parallel: true,
+		Run: func(ctx context.Context, data RunData) error {
+			started <- "b"
+			<-release
+			return nil
+		},
+	})
+
+	done := make(chan error, 1)
+	go func() { done <- r.Run(nil) }()
+
+	seen := map[string]bool{}
+	timeout := time.After(2 * time.Second)
+	for len(seen) < 2 {
+		select {
+		case name := <-started:
+			seen[name] = true
+		case <-timeout:
+			t.Fatalf("超时: 只有%d个阶段进入了Run, 期望两个并发阶段都已开始执行", len(seen))
+		}
+	}
+	close(release)
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run返回了意料之外的错误: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("超时: release关闭后Run未能返回")
+	}
+}
+
+// TestRunStopsLevelOnFailure验证同一层级内某个阶段失败时, Run最终会把该错误返回给调用方,
+// 而不会继续推进到下一个调度层级。
+func TestRunStopsLevelOnFailure(t *testing.T) {
+	r := NewRunner()
+	r.SetDataInitializer(func(*cobra.Command, []string) (RunData, error) {
+		return struct{}{}, nil
+	})
+
+	var ranNext bool
+	r.AppendPhase(Phase{
+		Name: "fails",
+		Run: func(ctx context.Context, data RunData) error {
+			return errTestPhaseFailure
+		},
+	})
+	r.AppendPhase(Phase{
+		Name: "next",
+		Run: func(ctx context.Context, data RunData) error {
+			ranNext = true
+			return nil
+		},
+	})
+
+	if err := r.Run(nil); err == nil {
+		t.Fatal("期望Run在阶段失败时返回错误")
+	}
+	if ranNext {
+		t.Fatal("期望失败层级之后的阶段不会被执行")
+	}
+}
+
+var errTestPhaseFailure = &testPhaseError{}
+
+type testPhaseError struct{}
+
+func (e *testPhaseError) Error() string { return "模拟的阶段执行失败" }
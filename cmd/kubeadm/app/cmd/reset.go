@@ -17,6 +17,8 @@ limitations under the License.
 package cmd
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 
@@ -28,24 +30,28 @@ import (
 	"k8s.io/kubernetes/cmd/kubeadm/app/cmd/phases/workflow"
 	cmdutil "k8s.io/kubernetes/cmd/kubeadm/app/cmd/util"
 	kubeadmconstants "k8s.io/kubernetes/cmd/kubeadm/app/constants"
+	"k8s.io/kubernetes/cmd/kubeadm/app/preflight"
 	configutil "k8s.io/kubernetes/cmd/kubeadm/app/util/config"
 	utilruntime "k8s.io/kubernetes/cmd/kubeadm/app/util/runtime"
 
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
 
 	"github.com/lithammer/dedent"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	flag "github.com/spf13/pflag"
+	"sigs.k8s.io/yaml"
 )
 
 var (
 	iptablesCleanupInstructions = dedent.Dedent(`
-		重置过程不会重置或清除 iptables 规则或 IPVS 表。
-		如果您想要重设 iptables，您必须使用 iptables 命令手动重设。
-
-		如果你的集群是使用 IPVA 设置的，那么运行 ipvsadm --clear 重置你的系统 IPVS 表。
+		重置过程已经尝试通过cleanup-iptables/cleanup-ipvs阶段清除kube-proxy遗留的iptables规则
+		或IPVS虚拟服务(可通过--proxy-cleanup=none或--skip-phases跳过)。如果探测代理模式失败，
+		或者您的规则并非完全由kube-proxy创建，请自行用iptables/ipvsadm --clear确认清理结果。
 
 		重置过程不会清除 kubeconfig 文件，您必须手动删除它们。
 		请检查 $HOME/.kube/config 文件的内容。
@@ -58,33 +64,58 @@ var (
 
 // resetOptions defines all the options exposed via flags by kubeadm reset.
 type resetOptions struct {
-	certificatesDir       string
-	criSocketPath         string
-	forceReset            bool
-	ignorePreflightErrors []string
-	kubeconfigPath        string
+	certificatesDir                string
+	criSocketPath                  string
+	forceReset                     bool
+	ignorePreflightErrors          []string
+	kubeconfigPath                 string
+	removeExternalEtcdMember       bool
+	removeExternalEtcdMemberDryRun bool
+	etcdEndpoints                  []string
+	etcdCAFile                     string
+	etcdCertFile                   string
+	etcdKeyFile                    string
+	outputFormat                   string
+	preserveEtcdDataDir            bool
+	etcdSnapshotPath               string
+	proxyCleanupMode               string
+	dryRun                         bool
 }
 
 // resetData defines all the runtime information used when running the kubeadm reset workflow;
 // this data is shared across all the phases that are included in the workflow.
 type resetData struct {
-	certificatesDir       string
-	client                clientset.Interface
-	criSocketPath         string
-	forceReset            bool
-	ignorePreflightErrors sets.String
-	inputReader           io.Reader
-	outputWriter          io.Writer
-	cfg                   *kubeadmapi.InitConfiguration
-	dirsToClean           []string
+	certificatesDir                string
+	client                         clientset.Interface
+	criSocketPath                  string
+	forceReset                     bool
+	ignorePreflightErrors          sets.String
+	inputReader                    io.Reader
+	outputWriter                   io.Writer
+	cfg                            *kubeadmapi.InitConfiguration
+	dirsToClean                    []string
+	removeExternalEtcdMember       bool
+	removeExternalEtcdMemberDryRun bool
+	etcdEndpoints                  []string
+	etcdCAFile                     string
+	etcdCertFile                   string
+	etcdKeyFile                    string
+	outputFormat                   string
+	preserveEtcdDataDir            bool
+	etcdSnapshotPath               string
+	proxyCleanupMode               string
+	kubeConfigPath                 string
+	dryRun                         bool
 }
 
 // newResetOptions returns a struct ready for being used for creating cmd join flags.
 func newResetOptions() *resetOptions {
 	return &resetOptions{
-		certificatesDir: kubeadmapiv1.DefaultCertificatesDir,
-		forceReset:      false,
-		kubeconfigPath:  kubeadmconstants.GetAdminKubeConfigPath(),
+		certificatesDir:  kubeadmapiv1.DefaultCertificatesDir,
+		forceReset:       false,
+		kubeconfigPath:   kubeadmconstants.GetAdminKubeConfigPath(),
+		outputFormat:     "text",
+		proxyCleanupMode: "auto",
 	}
 }
 
@@ -125,14 +156,26 @@ func newResetData(cmd *cobra.Command, options *resetOptions, in io.Reader, out i
 	}
 
 	return &resetData{
-		certificatesDir:       options.certificatesDir,
-		client:                client,
-		criSocketPath:         criSocketPath,
-		forceReset:            options.forceReset,
-		ignorePreflightErrors: ignorePreflightErrorsSet,
-		inputReader:           in,
-		outputWriter:          out,
-		cfg:                   cfg,
+		certificatesDir:                options.certificatesDir,
+		client:                         client,
+		criSocketPath:                  criSocketPath,
+		forceReset:                     options.forceReset,
+		ignorePreflightErrors:          ignorePreflightErrorsSet,
+		inputReader:                    in,
+		outputWriter:                   out,
+		cfg:                            cfg,
+		removeExternalEtcdMember:       options.removeExternalEtcdMember,
+		removeExternalEtcdMemberDryRun: options.removeExternalEtcdMemberDryRun,
+		etcdEndpoints:                  options.etcdEndpoints,
+		etcdCAFile:                     options.etcdCAFile,
+		etcdCertFile:                   options.etcdCertFile,
+		etcdKeyFile:                    options.etcdKeyFile,
+		outputFormat:                   options.outputFormat,
+		preserveEtcdDataDir:            options.preserveEtcdDataDir,
+		etcdSnapshotPath:               options.etcdSnapshotPath,
+		proxyCleanupMode:               options.proxyCleanupMode,
+		kubeConfigPath:                 options.kubeconfigPath,
+		dryRun:                         options.dryRun,
 	}, nil
 }
 
@@ -153,10 +196,68 @@ func AddResetFlags(flagSet *flag.FlagSet, resetOptions *resetOptions) {
 		&resetOptions.forceReset, options.ForceReset, "f", false,
 		"Reset the node without prompting for confirmation.",
 	)
+	flagSet.BoolVarP(
+		&resetOptions.forceReset, "yes", "y", false,
+		"与--force/-f完全等价的别名，供CI、Ansible等以非tty方式驱动reset的场景使用，命名上更直观。",
+	)
 
 	options.AddKubeConfigFlag(flagSet, &resetOptions.kubeconfigPath)
 	options.AddIgnorePreflightErrorsFlag(flagSet, &resetOptions.ignorePreflightErrors)
 	cmdutil.AddCRISocketFlag(flagSet, &resetOptions.criSocketPath)
+
+	flagSet.BoolVar(
+		&resetOptions.removeExternalEtcdMember, "remove-external-etcd-member", resetOptions.removeExternalEtcdMember,
+		"从外部 etcd 集群中移除本机对应的成员。仅当该节点使用的是外部 etcd(即没有本地 etcd 静态 Pod 清单)时才需要。",
+	)
+	flagSet.BoolVar(
+		&resetOptions.removeExternalEtcdMemberDryRun, "remove-external-etcd-member-dry-run", resetOptions.removeExternalEtcdMemberDryRun,
+		"与 --remove-external-etcd-member 搭配使用；只打印外部 etcd 集群当前的成员列表，不做任何移除操作。",
+	)
+	flagSet.StringSliceVar(
+		&resetOptions.etcdEndpoints, "etcd-endpoints", resetOptions.etcdEndpoints,
+		"外部 etcd 集群的客户端 Endpoint 列表，与 --remove-external-etcd-member 搭配使用。",
+	)
+	flagSet.StringVar(
+		&resetOptions.etcdCAFile, "etcd-cafile", resetOptions.etcdCAFile,
+		"用于对外部 etcd 集群进行 TLS 认证的 CA 证书路径。",
+	)
+	flagSet.StringVar(
+		&resetOptions.etcdCertFile, "etcd-certfile", resetOptions.etcdCertFile,
+		"用于对外部 etcd 集群进行 TLS 认证的客户端证书路径。",
+	)
+	flagSet.StringVar(
+		&resetOptions.etcdKeyFile, "etcd-keyfile", resetOptions.etcdKeyFile,
+		"用于对外部 etcd 集群进行 TLS 认证的客户端私钥路径。",
+	)
+	flagSet.StringVarP(
+		&resetOptions.outputFormat, "output", "o", resetOptions.outputFormat,
+		"以何种格式打印每个阶段的执行进度，取值为: text|json|yaml|events。当取值为json或yaml时，"+
+			"每个阶段的开始/成功/失败都会额外以NDJSON的形式打印到标准输出一行，供自动化场景解析；"+
+			"取值为events时，在此基础上还会针对当前节点发布一条corev1.Event，可通过"+
+			"`kubectl get events`查看每个阶段在哪台主机上执行过。",
+	)
+	flagSet.BoolVar(
+		&resetOptions.preserveEtcdDataDir, "preserve-data-dir", resetOptions.preserveEtcdDataDir,
+		"跳过清除stacked etcd数据目录，为操作者在重置过程中保留一份可恢复的数据，而不是直接删除。",
+	)
+	flagSet.StringVar(
+		&resetOptions.etcdSnapshotPath, "etcd-snapshot", resetOptions.etcdSnapshotPath,
+		"在清除stacked etcd数据目录之前，先对本地etcd成员执行一次快照并保存到该路径。"+
+			"保存后会立即校验快照文件的哈希；如果快照保存或校验失败，重置将中止，除非同时指定了--force。",
+	)
+	flagSet.StringVar(
+		&resetOptions.proxyCleanupMode, "proxy-cleanup", resetOptions.proxyCleanupMode,
+		"cleanup-iptables/cleanup-ipvs两个阶段应该清理哪一种kube-proxy规则，取值为: "+
+			"none(两者都不清理，保留此前需要手动清理的行为)|iptables|ipvs|auto(默认，从集群上的"+
+			"kube-proxy ConfigMap中探测实际使用的模式；探测失败时两个阶段都会跳过)。",
+	)
+	flagSet.BoolVar(
+		&resetOptions.dryRun, "dry-run", resetOptions.dryRun,
+		"不执行任何阶段，只运行一次只读的root权限预检，然后打印一份重置计划，列出已知会被触碰的"+
+			"制品(证书目录、本地/外部etcd成员、kube-proxy清理模式等)；配合父命令的--output=json"+
+			"或--output=yaml可以把这份计划以相应格式打印，供自动化场景先行比对再决定是否真正执行"+
+			"reset；--output=events在此模式下不受支持(没有阶段事件可供发布)，会直接报错。",
+	)
 }
 
 // newCmdReset 返回kubeadm reset命令
@@ -170,11 +271,21 @@ func newCmdReset(in io.Reader, out io.Writer, resetOptions *resetOptions) *cobra
 		Use:   "reset",
 		Short: "尽力还原“kubeadm init”或“kubeadm join”对此主机所做的更改",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			switch resetOptions.outputFormat {
+			case "text", "json", "yaml", "events":
+			default:
+				return errors.Errorf("输出格式无效: %s", resetOptions.outputFormat)
+			}
+
 			c, err := resetRunner.InitData(args)
 			if err != nil {
 				return err
 			}
 
+			if data := c.(*resetData); data.dryRun {
+				return runResetDryRun(data)
+			}
+
 			err = resetRunner.Run(args)
 			if err != nil {
 				return err
@@ -199,6 +310,8 @@ func newCmdReset(in io.Reader, out io.Writer, resetOptions *resetOptions) *cobra
 	resetRunner.AppendPhase(phases.NewUpdateClusterStatus())
 	resetRunner.AppendPhase(phases.NewRemoveETCDMemberPhase())
 	resetRunner.AppendPhase(phases.NewCleanupNodePhase())
+	resetRunner.AppendPhase(phases.NewCleanupIPTablesPhase())
+	resetRunner.AppendPhase(phases.NewCleanupIPVSPhase())
 
 	// 设置数据构建器功能，运行整个 Workflow 或单个 Phase 时，Runner 都将使用该功能
 	resetRunner.SetDataInitializer(func(cmd *cobra.Command, args []string) (workflow.RunData, error) {
@@ -211,6 +324,89 @@ func newCmdReset(in io.Reader, out io.Writer, resetOptions *resetOptions) *cobra
 	return cmd
 }
 
+// ResetPlan是--dry-run模式下打印的重置计划，只描述resetData在不运行任何阶段的情况下就能静态
+// 推导出来的制品。本仓库目前还没有移植真实kubeadm里负责清理静态Pod清单、kubelet运行目录等内容
+// 的cleanup-node阶段(参见cmd/kubeadm/app/cmd/phases/reset目录)，因此这里无法像设计中那样枚举
+// 这部分路径，只能在Notes字段里如实说明这一局限，而不是假装计划是完整的。
+type ResetPlan struct {
+	CertificatesDir    string   `json:"certificatesDir"`
+	StackedEtcdDataDir string   `json:"stackedEtcdDataDir,omitempty"`
+	ExternalEtcdMember string   `json:"externalEtcdMember,omitempty"`
+	ProxyCleanupMode   string   `json:"proxyCleanupMode"`
+	Notes              []string `json:"notes"`
+}
+
+// buildResetPlan根据已经加载好的resetData构造一份ResetPlan，不访问磁盘、不连接etcd，
+// 只使用InitData阶段已经准备好的字段。
+func buildResetPlan(data *resetData) ResetPlan {
+	plan := ResetPlan{
+		CertificatesDir:  data.certificatesDir,
+		ProxyCleanupMode: data.proxyCleanupMode,
+		Notes: []string{
+			"kubeconfig文件(如$HOME/.kube/config)和CNI配置(/etc/cni/net.d)不会被reset清除，需要手动删除",
+			"本仓库尚未实现cleanup-node阶段，无法在试运行中枚举静态Pod清单目录、kubelet运行目录等制品",
+		},
+	}
+
+	switch {
+	case data.cfg != nil && data.cfg.Etcd.Local != nil:
+		plan.StackedEtcdDataDir = data.cfg.Etcd.Local.DataDir
+	case data.removeExternalEtcdMember && len(data.etcdEndpoints) > 0:
+		plan.ExternalEtcdMember = fmt.Sprintf("将尝试从%v移除本机在外部etcd集群中对应的成员", data.etcdEndpoints)
+	}
+
+	return plan
+}
+
+// runResetDryRun在--dry-run下代替完整的reset工作流运行：只执行一次preflight.RunRootCheckOnly做
+// 只读的root权限检查，然后打印重置计划，不调用resetRunner.Run，因此不会触碰任何实际的制品。
+//
+// --output=events在--dry-run下被拒绝: events模式依赖于针对每个实际执行过的阶段发布
+// corev1.Event(见k8sEventRecordingEmitter)，而--dry-run根本不会执行任何阶段、也不保证有一个
+// 可用的集群client，静态的ResetPlan无法映射成一连串阶段事件，伪造一份没有意义。
+func runResetDryRun(data *resetData) error {
+	if data.outputFormat == "events" {
+		return errors.New("--dry-run不支持--output=events: 试运行不会执行任何阶段，因而没有阶段事件可供发布")
+	}
+
+	if err := preflight.RunRootCheckOnly(data.ignorePreflightErrors); err != nil {
+		return err
+	}
+
+	plan := buildResetPlan(data)
+
+	switch data.outputFormat {
+	case "json":
+		out, err := json.MarshalIndent(plan, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(data.outputWriter, string(out))
+		return nil
+	case "yaml":
+		out, err := yaml.Marshal(plan)
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(data.outputWriter, string(out))
+		return nil
+	}
+
+	fmt.Fprintln(data.outputWriter, "[重置] 试运行: 不会执行任何实际的清理操作，以下是重置计划:")
+	fmt.Fprintf(data.outputWriter, "[重置]   证书目录: %s\n", plan.CertificatesDir)
+	if plan.StackedEtcdDataDir != "" {
+		fmt.Fprintf(data.outputWriter, "[重置]   本地etcd数据目录: %s\n", plan.StackedEtcdDataDir)
+	}
+	if plan.ExternalEtcdMember != "" {
+		fmt.Fprintf(data.outputWriter, "[重置]   %s\n", plan.ExternalEtcdMember)
+	}
+	fmt.Fprintf(data.outputWriter, "[重置]   kube-proxy规则清理模式: %s\n", plan.ProxyCleanupMode)
+	for _, note := range plan.Notes {
+		fmt.Fprintf(data.outputWriter, "[重置]   说明: %s\n", note)
+	}
+	return nil
+}
+
 func cleanDirs(data *resetData) {
 	fmt.Printf("[reset] Deleting contents of stateful directories: %v\n", data.dirsToClean)
 	for _, dir := range data.dirsToClean {
@@ -261,6 +457,141 @@ func (r *resetData) CRISocketPath() string {
 	return r.criSocketPath
 }
 
+// RemoveExternalEtcdMember returns whether the remove-etcd-member phase should remove this node's member
+// from an external etcd cluster via --etcd-endpoints instead of relying on the local etcd static Pod manifest.
+func (r *resetData) RemoveExternalEtcdMember() bool {
+	return r.removeExternalEtcdMember
+}
+
+// RemoveExternalEtcdMemberDryRun returns whether the remove-etcd-member phase should only list the external
+// etcd cluster's members instead of removing one.
+func (r *resetData) RemoveExternalEtcdMemberDryRun() bool {
+	return r.removeExternalEtcdMemberDryRun
+}
+
+// EtcdEndpoints returns the client endpoints of the external etcd cluster.
+func (r *resetData) EtcdEndpoints() []string {
+	return r.etcdEndpoints
+}
+
+// EtcdCAFile returns the CA certificate path used to authenticate against the external etcd cluster.
+func (r *resetData) EtcdCAFile() string {
+	return r.etcdCAFile
+}
+
+// EtcdCertFile returns the client certificate path used to authenticate against the external etcd cluster.
+func (r *resetData) EtcdCertFile() string {
+	return r.etcdCertFile
+}
+
+// EtcdKeyFile returns the client key path used to authenticate against the external etcd cluster.
+func (r *resetData) EtcdKeyFile() string {
+	return r.etcdKeyFile
+}
+
+// PreserveEtcdDataDir returns whether the remove-etcd-member phase should skip cleaning up the
+// stacked etcd data directory instead of adding it to the list of directories to remove.
+func (r *resetData) PreserveEtcdDataDir() bool {
+	return r.preserveEtcdDataDir
+}
+
+// EtcdSnapshotPath returns the path the remove-etcd-member phase should save a local etcd snapshot
+// to before cleaning up the data directory, or an empty string if no snapshot should be taken.
+func (r *resetData) EtcdSnapshotPath() string {
+	return r.etcdSnapshotPath
+}
+
+// ProxyCleanupMode returns the value of --proxy-cleanup: "none", "iptables", "ipvs" or "auto".
+func (r *resetData) ProxyCleanupMode() string {
+	return r.proxyCleanupMode
+}
+
+// KubeConfigPath返回reset阶段用来访问集群的admin kubeconfig路径，供update-cluster-status阶段
+// 构建访问ClusterStatus自定义资源所需的dynamic client。
+func (r *resetData) KubeConfigPath() string {
+	return r.kubeConfigPath
+}
+
+// EventEmitter实现workflow.EventEmitterProvider。当--output被设置为json或yaml时，返回一个
+// 把每个阶段的PhaseEvent以NDJSON形式写入outputWriter的EventEmitter，与既有的面向人类的阶段
+// 提示(如remove-etcd-member阶段打印的etcd成员信息)并行输出；--output为默认值(text)时不发出
+// 结构化事件。--output为events时，在NDJSON的基础上再包一层k8sEventRecordingEmitter，针对
+// 本节点额外发布corev1.Event，供kubectl get events这类已有工具直接观察每个阶段的执行情况。
+func (r *resetData) EventEmitter() workflow.EventEmitter {
+	if r.outputFormat != "json" && r.outputFormat != "yaml" && r.outputFormat != "events" {
+		return nil
+	}
+
+	emitter := workflow.EventEmitter(&workflow.NDJSONEventEmitter{Writer: r.outputWriter})
+	if r.outputFormat != "events" {
+		return emitter
+	}
+
+	nodeName := ""
+	if r.cfg != nil {
+		nodeName = r.cfg.NodeRegistration.Name
+	}
+	return &k8sEventRecordingEmitter{
+		inner:    emitter,
+		client:   r.client,
+		nodeName: nodeName,
+	}
+}
+
+// k8sEventRecordingEmitter包装另一个workflow.EventEmitter，在转发每条PhaseEvent的同时，还针对
+// nodeName对应的Node对象发布一条corev1.Event，使reset的执行进度可以通过`kubectl get events`
+// 这类已有的集群内工具观察，而不必采集NDJSON输出。client或nodeName为空(例如本地admin
+// kubeconfig已经不可用)时跳过发布事件，只转发给inner，不影响reset本身的执行。
+type k8sEventRecordingEmitter struct {
+	inner    workflow.EventEmitter
+	client   clientset.Interface
+	nodeName string
+}
+
+// EmitPhaseEvent实现workflow.EventEmitter。发布corev1.Event失败时只记录一条警告日志，不会中断
+// reset流程，也不会影响inner的输出。
+func (e *k8sEventRecordingEmitter) EmitPhaseEvent(event workflow.PhaseEvent) {
+	if e.inner != nil {
+		e.inner.EmitPhaseEvent(event)
+	}
+
+	if e.client == nil || e.nodeName == "" {
+		return
+	}
+
+	eventType := corev1.EventTypeNormal
+	if event.Status == workflow.PhaseEventStatusFailed {
+		eventType = corev1.EventTypeWarning
+	}
+	message := fmt.Sprintf("kubeadm reset阶段%s", event.Phase)
+	if event.SubPhase != "" {
+		message = fmt.Sprintf("kubeadm reset阶段%s", event.SubPhase)
+	}
+	if event.Err != "" {
+		message = fmt.Sprintf("%s: %s", message, event.Err)
+	}
+
+	kubeEvent := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "kubeadm-reset-",
+			Namespace:    metav1.NamespaceDefault,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			Kind: "Node",
+			Name: e.nodeName,
+		},
+		Reason:         string(event.Status),
+		Message:        message,
+		Type:           eventType,
+		FirstTimestamp: metav1.NewTime(event.StartedAt),
+		LastTimestamp:  metav1.NewTime(event.StartedAt),
+		Source:         corev1.EventSource{Component: "kubeadm"},
+	}
+	if _, err := e.client.CoreV1().Events(metav1.NamespaceDefault).Create(context.TODO(), kubeEvent, metav1.CreateOptions{}); err != nil {
+		klog.Warningf("[reset] 无法针对节点%s发布事件: %v", e.nodeName, err)
+	}
+}
+
 func resetDetectCRISocket(cfg *kubeadmapi.InitConfiguration) (string, error) {
 	if cfg != nil {
 		// first try to get the CRI socket from the cluster configuration
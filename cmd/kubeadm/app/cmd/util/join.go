@@ -19,6 +19,7 @@ package util
 import (
 	"bytes"
 	"crypto/x509"
+	"encoding/json"
 	"html/template"
 	"strings"
 
@@ -29,6 +30,7 @@ import (
 	clientcertutil "k8s.io/client-go/util/cert"
 
 	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
 )
 
 // join命令的字符串模板, 可以被填充解析为字符串
@@ -38,28 +40,87 @@ var joinCommandTemplate = template.Must(template.New("join").Parse(`` +
 	--control-plane {{if .CertificateKey}}--certificate-key {{.CertificateKey}}{{end}}{{end}}`,
 ))
 
+// JoinCredentials is the typed equivalent of the "kubeadm join" shell command: the control-plane endpoint
+// plus the token, CA cert pins and (optionally) certificate key a joining node needs. Automation (Ansible,
+// Terraform, Cluster API providers) can consume it directly as JSON/YAML instead of regexing a shell
+// command back apart to recover the same values.
+type JoinCredentials struct {
+	ControlPlaneHostPort string   `json:"controlPlaneHostPort"`
+	Token                string   `json:"token"`
+	CAPubKeyPins         []string `json:"caCertHashes"`
+	CertificateKey       string   `json:"certificateKey,omitempty"`
+	ControlPlane         bool     `json:"controlPlane"`
+}
+
+// ShellCommand renders the credentials as the "kubeadm join ..." command an operator would run on the
+// joining node. skipTokenPrint/skipCertificateKeyPrint withhold the corresponding secret from the rendered
+// text (substituting "<value withheld>") while still producing a syntactically valid command line.
+func (j *JoinCredentials) ShellCommand(skipTokenPrint, skipCertificateKeyPrint bool) (string, error) {
+	ctx := map[string]interface{}{
+		"Token":                j.Token,
+		"CAPubKeyPins":         j.CAPubKeyPins,
+		"ControlPlaneHostPort": j.ControlPlaneHostPort,
+		"CertificateKey":       j.CertificateKey,
+		"ControlPlane":         j.ControlPlane,
+	}
+
+	if skipTokenPrint {
+		ctx["Token"] = template.HTML("<value withheld>")
+	}
+	if skipCertificateKeyPrint {
+		ctx["CertificateKey"] = template.HTML("<value withheld>")
+	}
+
+	var out bytes.Buffer
+	if err := joinCommandTemplate.Execute(&out, ctx); err != nil {
+		return "", errors.Wrap(err, "无法渲染join命令模板")
+	}
+	return out.String(), nil
+}
+
+// JSON marshals the credentials as indented JSON.
+func (j *JoinCredentials) JSON() ([]byte, error) {
+	return json.MarshalIndent(j, "", "  ")
+}
+
+// YAML marshals the credentials as YAML.
+func (j *JoinCredentials) YAML() ([]byte, error) {
+	return yaml.Marshal(j)
+}
+
 // GetJoinWorkerCommand returns the kubeadm join command for a given token and
 // and Kubernetes cluster (the current cluster in the kubeconfig file)
 func GetJoinWorkerCommand(kubeConfigFile, token string, skipTokenPrint bool) (string, error) {
-	return getJoinCommand(kubeConfigFile, token, "", false, skipTokenPrint, false)
+	creds, err := GetJoinCredentials(kubeConfigFile, token, "", false)
+	if err != nil {
+		return "", err
+	}
+	return creds.ShellCommand(skipTokenPrint, false)
 }
 
 // GetJoinControlPlaneCommand 返回给定令牌和Kubernetes集群（kubeconfig文件中的当前集群）的kubeadm join命令
 func GetJoinControlPlaneCommand(kubeConfigFile, token, key string, skipTokenPrint, skipCertificateKeyPrint bool) (string, error) {
-	return getJoinCommand(kubeConfigFile, token, key, true, skipTokenPrint, skipCertificateKeyPrint)
+	creds, err := GetJoinCredentials(kubeConfigFile, token, key, true)
+	if err != nil {
+		return "", err
+	}
+	return creds.ShellCommand(skipTokenPrint, skipCertificateKeyPrint)
 }
 
-func getJoinCommand(kubeConfigFile, token, key string, controlPlane, skipTokenPrint, skipCertificateKeyPrint bool) (string, error) {
+// GetJoinCredentials 从kubeconfig文件中收集加入此集群所需的全部凭据（控制平面端点、令牌、CA证书Pin以及证书密钥），
+// 并以结构化的形式返回，供GetJoinWorkerCommand/GetJoinControlPlaneCommand渲染为Shell命令，
+// 也可以直接序列化为JSON/YAML供自动化工具使用。
+func GetJoinCredentials(kubeConfigFile, token, key string, controlPlane bool) (*JoinCredentials, error) {
 	// 加载kubeconfig文件以获取CA证书和端点
 	config, err := clientcmd.LoadFromFile(kubeConfigFile)
 	if err != nil {
-		return "", errors.Wrap(err, "未能加载kubeconfig")
+		return nil, errors.Wrap(err, "未能加载kubeconfig")
 	}
 
 	// 加载默认的集群配置
 	clusterConfig := kubeconfigutil.GetClusterFromKubeConfig(config)
 	if clusterConfig == nil {
-		return "", errors.New("无法获取默认群集配置")
+		return nil, errors.New("无法获取默认群集配置")
 	}
 
 	// 从kubeconfig加载CA证书（从PEM数据或通过文件路径）
@@ -68,15 +129,15 @@ func getJoinCommand(kubeConfigFile, token, key string, controlPlane, skipTokenPr
 	if clusterConfig.CertificateAuthorityData != nil {
 		caCerts, err = clientcertutil.ParseCertsPEM(clusterConfig.CertificateAuthorityData)
 		if err != nil {
-			return "", errors.Wrap(err, "无法从kubeconfig解析CA证书")
+			return nil, errors.Wrap(err, "无法从kubeconfig解析CA证书")
 		}
 	} else if clusterConfig.CertificateAuthority != "" {
 		caCerts, err = clientcertutil.CertsFromFile(clusterConfig.CertificateAuthority)
 		if err != nil {
-			return "", errors.Wrap(err, "无法加载kubeconfig引用的CA证书")
+			return nil, errors.Wrap(err, "无法加载kubeconfig引用的CA证书")
 		}
 	} else {
-		return "", errors.New("在kubeconfig中未找到CA证书")
+		return nil, errors.New("在kubeconfig中未找到CA证书")
 	}
 
 	// 散列所有CA证书，并将其公钥PIN作为可信值包含在内
@@ -85,25 +146,11 @@ func getJoinCommand(kubeConfigFile, token, key string, controlPlane, skipTokenPr
 		publicKeyPins = append(publicKeyPins, pubkeypin.Hash(caCert))
 	}
 
-	ctx := map[string]interface{}{
-		"Token":                token,
-		"CAPubKeyPins":         publicKeyPins,
-		"ControlPlaneHostPort": strings.Replace(clusterConfig.Server, "https://", "", -1),
-		"CertificateKey":       key,
-		"ControlPlane":         controlPlane,
-	}
-
-	if skipTokenPrint {
-		ctx["Token"] = template.HTML("<value withheld>")
-	}
-	if skipCertificateKeyPrint {
-		ctx["CertificateKey"] = template.HTML("<value withheld>")
-	}
-
-	var out bytes.Buffer
-	err = joinCommandTemplate.Execute(&out, ctx)
-	if err != nil {
-		return "", errors.Wrap(err, "无法渲染join命令模板")
-	}
-	return out.String(), nil
+	return &JoinCredentials{
+		ControlPlaneHostPort: strings.Replace(clusterConfig.Server, "https://", "", -1),
+		Token:                token,
+		CAPubKeyPins:         publicKeyPins,
+		CertificateKey:       key,
+		ControlPlane:         controlPlane,
+	}, nil
 }
@@ -22,6 +22,10 @@ import (
 	"io"
 
 	apimachineryversion "k8s.io/apimachinery/pkg/version"
+	kubeadmconstants "k8s.io/kubernetes/cmd/kubeadm/app/constants"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/component-base/version"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/yaml"
@@ -33,26 +37,37 @@ import (
 // Version 提供kubeadm的版本信息
 type Version struct {
 	ClientVersion *apimachineryversion.Info `json:"clientVersion"`
+	// ServerVersion是通过--kubeconfig(默认admin.conf)连接到的集群所报告的API Server版本；
+	// --client-only时，或者连接/查询失败时为nil，不会导致命令本身失败。
+	ServerVersion *apimachineryversion.Info `json:"serverVersion,omitempty"`
 }
 
 // newCmdVersion 提供kubeadm的版本信息
 func newCmdVersion(out io.Writer) *cobra.Command {
+	var clientOnly bool
+	var kubeconfigPath string
+
 	cmd := &cobra.Command{
 		Use:   "version",
 		Short: "打印kubeadm的版本信息",
 		Long:  "打印kubeadm相关的详细版本信息",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			fmt.Println("执行: cmd/kubeadm/app/cmd/version.go[newCmdVersion][RunE]")
-			return RunVersion(out, cmd)
+			return RunVersion(out, cmd, clientOnly, kubeconfigPath)
 		},
 		Args: cobra.NoArgs,
 	}
 	cmd.Flags().StringP("output", "o", "", "输出格式, 可用的选项有: 'yaml', 'json' and 'short'")
+	cmd.Flags().BoolVar(&clientOnly, "client-only", false, "只打印kubeadm客户端自身的版本信息，不尝试连接集群获取API Server版本。")
+	cmd.Flags().StringVar(&kubeconfigPath, "kubeconfig", kubeadmconstants.GetAdminKubeConfigPath(), "指定用于连接集群、获取API Server版本的kubeconfig文件路径。")
 	return cmd
 }
 
-// RunVersion 提供kubeadm的版本信息，格式取决于cobra.Command中指定的参数
-func RunVersion(out io.Writer, cmd *cobra.Command) error {
+// RunVersion 提供kubeadm的版本信息，格式取决于cobra.Command中指定的参数。clientOnly为false时，
+// 还会尝试用kubeconfigPath连接集群、通过Discovery().ServerVersion()读取API Server的版本，
+// 用于post-upgrade时比对客户端与服务端的版本偏差；连接或查询失败时只在V(1)记一条日志，
+// ServerVersion留空，不会让命令本身失败。
+func RunVersion(out io.Writer, cmd *cobra.Command, clientOnly bool, kubeconfigPath string) error {
 	fmt.Println("执行: cmd/kubeadm/app/cmd/version.go[newCmdVersion][RunVersion]")
 	klog.V(1).Infoln("[版本] 正在检索版本信息")
 	// 返回整个代码基版本, 它是用来检测二进制代码是用什么代码构建的。
@@ -73,6 +88,10 @@ func RunVersion(out io.Writer, cmd *cobra.Command) error {
 	v.ClientVersion.Compiler = "gc"
 	v.ClientVersion.Platform = "linux/amd64"
 
+	if !clientOnly {
+		v.ServerVersion = getServerVersion(kubeconfigPath)
+	}
+
 	const flag = "output"
 	of, err := cmd.Flags().GetString(flag)
 	if err != nil {
@@ -82,8 +101,14 @@ func RunVersion(out io.Writer, cmd *cobra.Command) error {
 	switch of {
 	case "":
 		_, _ = fmt.Fprintf(out, "kubeadm 版本: %#v\n", v.ClientVersion)
+		if v.ServerVersion != nil {
+			_, _ = fmt.Fprintf(out, "API Server 版本: %#v\n", v.ServerVersion)
+		}
 	case "short":
 		_, _ = fmt.Fprintf(out, "%s\n", v.ClientVersion.GitVersion)
+		if v.ServerVersion != nil {
+			_, _ = fmt.Fprintf(out, "%s\n", v.ServerVersion.GitVersion)
+		}
 	case "yaml":
 		y, err := yaml.Marshal(&v)
 		if err != nil {
@@ -102,3 +127,28 @@ func RunVersion(out io.Writer, cmd *cobra.Command) error {
 
 	return nil
 }
+
+// getServerVersion尝试用kubeconfigPath连接集群并读取API Server的版本，供"kubeadm version"在
+// 升级前后核对客户端/服务端版本是否匹配。任何一步失败(文件不存在、集群连不上、API Server没有
+// 实现/version等)都只在V(1)记一条日志并返回nil，不让"kubeadm version"本身失败——离线场景下
+// (没有admin.conf，或者不在控制平面节点上运行)这是预期情况，不是错误。
+func getServerVersion(kubeconfigPath string) *apimachineryversion.Info {
+	restConfig, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+	if err != nil {
+		klog.V(1).Infof("[版本] 无法从%q构建集群连接配置，跳过API Server版本查询: %v", kubeconfigPath, err)
+		return nil
+	}
+
+	client, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		klog.V(1).Infof("[版本] 无法创建客户端，跳过API Server版本查询: %v", err)
+		return nil
+	}
+
+	serverVersion, err := client.Discovery().ServerVersion()
+	if err != nil {
+		klog.V(1).Infof("[版本] 无法获取API Server版本: %v", err)
+		return nil
+	}
+	return serverVersion
+}
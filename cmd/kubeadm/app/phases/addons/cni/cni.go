@@ -0,0 +1,147 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cni内嵌了kubeadm init在--pod-network-addon阶段可以直接应用的一小份"已知可用"Pod网络插件清单，
+// 免去了用户在initDoneTempl中被告知"请自行下载并apply一份YAML"这一步。清单按插件名、再按kubeadm次版本号
+// (例如"1.26")分类维护，同一插件在不同Kubernetes次版本上使用的镜像tag或API版本可能不同。
+package cni
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+// TemplateData是渲染内嵌清单时可以使用的占位符集合。
+type TemplateData struct {
+	// PodCIDR是集群的Pod网段，对应ClusterConfiguration.Networking.PodSubnet。
+	PodCIDR string
+	// DNSDomain是集群的DNS域名，对应ClusterConfiguration.Networking.DNSDomain。
+	DNSDomain string
+}
+
+// Addon描述一个可选的Pod网络插件：它在目录中的名字、清单中DaemonSet的命名空间与名称（用于阶段结束前
+// 等待其Ready），以及按kubeadm次版本号索引的清单模板。
+type Addon struct {
+	// Namespace是该插件DaemonSet所在的命名空间。
+	Namespace string
+	// DaemonSetName是该插件负责运行CNI插件二进制与网络代理的DaemonSet名称。
+	DaemonSetName string
+	// manifestsByMinorVersion按"<major>.<minor>"索引该插件在对应Kubernetes版本下已验证可用的清单；
+	// "default"是在找不到匹配的次版本号时使用的兜底清单。
+	manifestsByMinorVersion map[string]string
+}
+
+// None是--pod-network-addon的特殊取值，表示不安装任何Pod网络插件，维持此前需要用户手动apply的行为。
+const None = "none"
+
+// catalog是受支持的Pod网络插件目录，键为--pod-network-addon接受的插件名。
+var catalog = map[string]Addon{
+	"calico": {
+		Namespace:     "kube-system",
+		DaemonSetName: "calico-node",
+		manifestsByMinorVersion: map[string]string{
+			"default": calicoManifest,
+		},
+	},
+	"cilium": {
+		Namespace:     "kube-system",
+		DaemonSetName: "cilium",
+		manifestsByMinorVersion: map[string]string{
+			"default": ciliumManifest,
+		},
+	},
+	"flannel": {
+		Namespace:     "kube-flannel",
+		DaemonSetName: "kube-flannel-ds",
+		manifestsByMinorVersion: map[string]string{
+			"default": flannelManifest,
+		},
+	},
+	"weave": {
+		Namespace:     "kube-system",
+		DaemonSetName: "weave-net",
+		manifestsByMinorVersion: map[string]string{
+			"default": weaveManifest,
+		},
+	},
+}
+
+// KnownAddons返回目录中收录的全部插件名，用于--pod-network-addon的取值校验与帮助文本。
+func KnownAddons() []string {
+	names := make([]string, 0, len(catalog))
+	for name := range catalog {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Get按插件名返回目录中的条目；ok为false表示该插件名不在目录中。
+func Get(addonName string) (Addon, bool) {
+	addon, ok := catalog[addonName]
+	return addon, ok
+}
+
+// Manifest返回addon在kubernetesVersion对应次版本号下的清单模板；找不到该次版本号专属的清单时回退到
+// "default"清单。
+func (a Addon) Manifest(kubernetesVersion string) (string, error) {
+	minor := minorVersion(kubernetesVersion)
+	if manifest, ok := a.manifestsByMinorVersion[minor]; ok {
+		return manifest, nil
+	}
+	if manifest, ok := a.manifestsByMinorVersion["default"]; ok {
+		return manifest, nil
+	}
+	return "", errors.Errorf("目录中没有为Kubernetes %s收录清单", kubernetesVersion)
+}
+
+// Render用data中的占位符渲染manifest，返回渲染后、可以直接按"---"切分为多个对象的多文档YAML文本。
+func Render(manifest string, data TemplateData) (string, error) {
+	tmpl, err := template.New("cni-addon").Parse(manifest)
+	if err != nil {
+		return "", errors.Wrap(err, "内嵌的CNI清单模板解析失败")
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", errors.Wrap(err, "渲染CNI清单失败")
+	}
+	return buf.String(), nil
+}
+
+// SplitManifests把一份用"---"分隔的多文档YAML文本切分成若干文档，忽略切分后为空白的部分。
+func SplitManifests(manifest string) []string {
+	docs := []string{}
+	for _, doc := range strings.Split(manifest, "\n---\n") {
+		if strings.TrimSpace(doc) == "" {
+			continue
+		}
+		docs = append(docs, doc)
+	}
+	return docs
+}
+
+// minorVersion从形如"v1.26.3"或"1.26.3"的Kubernetes版本号中提取"<major>.<minor>"，用于在目录中查找
+// 该版本专属的清单；无法解析时返回"default"以便调用方回退到兜底清单。
+func minorVersion(kubernetesVersion string) string {
+	v := strings.TrimPrefix(kubernetesVersion, "v")
+	parts := strings.Split(v, ".")
+	if len(parts) < 2 {
+		return "default"
+	}
+	return parts[0] + "." + parts[1]
+}
@@ -0,0 +1,229 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cni
+
+// 以下清单是各插件官方安装文档中推荐清单的精简版本：只保留让DaemonSet跑起来所必需的对象
+// (ServiceAccount、最基本的RBAC、配置该插件所需的ConfigMap、DaemonSet本身)，
+// 并用{{ .PodCIDR }}/{{ .DNSDomain }}占位符替换了原本写死的网段与DNS域名，
+// 而不是照搬数百行的完整官方manifest。
+
+const calicoManifest = `
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: calico-node
+  namespace: kube-system
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: calico-config
+  namespace: kube-system
+data:
+  cluster_cidr: "{{ .PodCIDR }}"
+  dns_domain: "{{ .DNSDomain }}"
+---
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: calico-node
+  namespace: kube-system
+  labels:
+    k8s-app: calico-node
+spec:
+  selector:
+    matchLabels:
+      k8s-app: calico-node
+  template:
+    metadata:
+      labels:
+        k8s-app: calico-node
+    spec:
+      serviceAccountName: calico-node
+      hostNetwork: true
+      tolerations:
+        - effect: NoSchedule
+          operator: Exists
+        - key: node-role.kubernetes.io/control-plane
+          effect: NoSchedule
+      containers:
+        - name: calico-node
+          image: docker.io/calico/node:v3.25.0
+          env:
+            - name: CALICO_IPV4POOL_CIDR
+              value: "{{ .PodCIDR }}"
+            - name: CLUSTER_DNS_DOMAIN
+              value: "{{ .DNSDomain }}"
+          securityContext:
+            privileged: true
+`
+
+const ciliumManifest = `
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: cilium
+  namespace: kube-system
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cilium-config
+  namespace: kube-system
+data:
+  cluster-pool-ipv4-cidr: "{{ .PodCIDR }}"
+  cluster-dns-domain: "{{ .DNSDomain }}"
+---
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: cilium
+  namespace: kube-system
+  labels:
+    k8s-app: cilium
+spec:
+  selector:
+    matchLabels:
+      k8s-app: cilium
+  template:
+    metadata:
+      labels:
+        k8s-app: cilium
+    spec:
+      serviceAccountName: cilium
+      hostNetwork: true
+      tolerations:
+        - effect: NoSchedule
+          operator: Exists
+        - key: node-role.kubernetes.io/control-plane
+          effect: NoSchedule
+      containers:
+        - name: cilium-agent
+          image: quay.io/cilium/cilium:v1.13.0
+          env:
+            - name: CILIUM_CLUSTER_POOL_IPV4_CIDR
+              value: "{{ .PodCIDR }}"
+            - name: CILIUM_CLUSTER_DNS_DOMAIN
+              value: "{{ .DNSDomain }}"
+          securityContext:
+            privileged: true
+`
+
+const flannelManifest = `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: kube-flannel
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: flannel
+  namespace: kube-flannel
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: kube-flannel-cfg
+  namespace: kube-flannel
+data:
+  net-conf.json: |
+    {
+      "Network": "{{ .PodCIDR }}",
+      "Backend": {
+        "Type": "vxlan"
+      }
+    }
+---
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: kube-flannel-ds
+  namespace: kube-flannel
+  labels:
+    app: flannel
+spec:
+  selector:
+    matchLabels:
+      app: flannel
+  template:
+    metadata:
+      labels:
+        app: flannel
+    spec:
+      serviceAccountName: flannel
+      hostNetwork: true
+      tolerations:
+        - effect: NoSchedule
+          operator: Exists
+        - key: node-role.kubernetes.io/control-plane
+          effect: NoSchedule
+      containers:
+        - name: kube-flannel
+          image: docker.io/flannel/flannel:v0.21.4
+          securityContext:
+            privileged: true
+`
+
+const weaveManifest = `
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: weave-net
+  namespace: kube-system
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: weave-net-config
+  namespace: kube-system
+data:
+  ipalloc_range: "{{ .PodCIDR }}"
+  dns_domain: "{{ .DNSDomain }}"
+---
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: weave-net
+  namespace: kube-system
+  labels:
+    name: weave-net
+spec:
+  selector:
+    matchLabels:
+      name: weave-net
+  template:
+    metadata:
+      labels:
+        name: weave-net
+    spec:
+      serviceAccountName: weave-net
+      hostNetwork: true
+      tolerations:
+        - effect: NoSchedule
+          operator: Exists
+        - key: node-role.kubernetes.io/control-plane
+          effect: NoSchedule
+      containers:
+        - name: weave
+          image: docker.io/weaveworks/weave-kube:2.8.1
+          env:
+            - name: IPALLOC_RANGE
+              value: "{{ .PodCIDR }}"
+          securityContext:
+            privileged: true
+`
@@ -0,0 +1,100 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyprovider
+
+import (
+	"context"
+	"crypto"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// KMSClient是kms Provider依赖的最小签名接口。AWS KMS、GCP Cloud KMS、Azure Key Vault各自的
+// 客户端只需实现这两个方法就能注册为一个kms后端；具体的云SDK调用留给各自的后端实现，本文件不
+// 内置、也不直接依赖任何一家云厂商的SDK。
+type KMSClient interface {
+	// Public返回keyID对应的公钥。
+	Public(ctx context.Context, keyID string) (crypto.PublicKey, error)
+	// Sign对digest签名，opts携带哈希算法等信息，语义与crypto.Signer.Sign一致。
+	Sign(ctx context.Context, keyID string, digest []byte, opts crypto.SignerOpts) ([]byte, error)
+}
+
+// KMSBackendFactory按kms URI中backend前缀之后剩余的部分构造一个具体云厂商的KMSClient与
+// 其上的密钥ID，例如"kms:aws:///alias/kubeadm-ca"里，backend为"aws"，rest为
+// "///alias/kubeadm-ca"。
+type KMSBackendFactory func(rest string) (client KMSClient, keyID string, err error)
+
+var kmsBackends = map[string]KMSBackendFactory{}
+
+// RegisterKMSBackend登记一个云厂商后端。kubeadm核心不内置任何后端——AWS/GCP/Azure各自的KMS
+// 集成应当在各自的插件包里于init()中调用本函数注册，这里只提供按backend名分发的注册表。
+func RegisterKMSBackend(backend string, factory KMSBackendFactory) {
+	kmsBackends[backend] = factory
+}
+
+type kmsProvider struct {
+	client KMSClient
+	keyID  string
+}
+
+func (p *kmsProvider) Name() string          { return "kms" }
+func (p *kmsProvider) WritesKeyToDisk() bool { return false }
+
+func (p *kmsProvider) Signer() (crypto.Signer, error) {
+	pub, err := p.client.Public(context.Background(), p.keyID)
+	if err != nil {
+		return nil, errors.Wrap(err, "无法获取KMS密钥的公钥")
+	}
+	return &kmsSigner{client: p.client, keyID: p.keyID, public: pub}, nil
+}
+
+// newKMSProvider解析形如"kms:aws:///alias/kubeadm-ca"的URI：冒号之后到下一个冒号之前是已经
+// 通过RegisterKMSBackend注册过的后端名。
+func newKMSProvider(uri string) (Provider, error) {
+	rest := strings.TrimPrefix(uri, "kms:")
+	parts := strings.SplitN(rest, ":", 2)
+	if len(parts) != 2 {
+		return nil, errors.Errorf("kms URI %q缺少云厂商后端前缀，期望形如kms:<backend>:...", uri)
+	}
+
+	factory, ok := kmsBackends[parts[0]]
+	if !ok {
+		return nil, errors.Errorf("没有注册名为%q的KMS后端，请先调用keyprovider.RegisterKMSBackend", parts[0])
+	}
+
+	client, keyID, err := factory(parts[1])
+	if err != nil {
+		return nil, errors.Wrapf(err, "初始化KMS后端%q失败", parts[0])
+	}
+	return &kmsProvider{client: client, keyID: keyID}, nil
+}
+
+// kmsSigner把KMSClient适配成标准库crypto.Signer，使得原本使用本地*rsa.PrivateKey/
+// *ecdsa.PrivateKey签名的代码无需改变调用方式即可切换到远程签名。
+type kmsSigner struct {
+	client KMSClient
+	keyID  string
+	public crypto.PublicKey
+}
+
+func (s *kmsSigner) Public() crypto.PublicKey { return s.public }
+
+func (s *kmsSigner) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	return s.client.Sign(context.Background(), s.keyID, digest, opts)
+}
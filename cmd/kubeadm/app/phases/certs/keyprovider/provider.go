@@ -0,0 +1,75 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package keyprovider抽象了CA私钥究竟保存在哪里：本地磁盘PEM文件(file，默认，完整复现历史行
+// 为)，还是HSM(pkcs11，通过github.com/ThalesIgnite/crypto11)、云KMS(kms，通过可插拔的签名后
+// 端)。certs阶段按--ca-key-provider-uri这个命令行参数(见cmd/init.go的initData.CAKeyProviderURI)
+// 选择Provider；kubeadmapi.ClusterConfiguration没有与之对应的字段，因此没有走配置文件这条路径。
+package keyprovider
+
+import (
+	"crypto"
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+// Provider为一张CA证书提供签名能力，并声明该CA的私钥是否应当被写入磁盘。
+type Provider interface {
+	// Name返回Provider的scheme，如"file"、"pkcs11"、"kms"。
+	Name() string
+	// WritesKeyToDisk为true时，CreateCACertAndKeyFiles按历史行为把私钥写成PEM文件；为false
+	// 时只写CA证书，私钥留在Provider背后的HSM/KMS中。
+	WritesKeyToDisk() bool
+	// Signer返回用于签发下级证书的crypto.Signer。file Provider没有远程签名能力，返回
+	// ErrLocalSigningOnly，调用方应改为从磁盘加载私钥自行签名，这是目前唯一支持的路径。
+	Signer() (crypto.Signer, error)
+}
+
+// ErrLocalSigningOnly由file Provider的Signer返回，表示应当走磁盘私钥签名这条原有路径。
+var ErrLocalSigningOnly = errors.New("file provider的CA私钥只存在于磁盘，没有远程签名能力")
+
+// fileProvider是默认Provider，完整复现引入CAKeyProvider之前的行为：CA私钥与证书一起写到磁盘。
+type fileProvider struct{}
+
+func (fileProvider) Name() string                   { return "file" }
+func (fileProvider) WritesKeyToDisk() bool          { return true }
+func (fileProvider) Signer() (crypto.Signer, error) { return nil, ErrLocalSigningOnly }
+
+// ParseURI按--ca-key-provider-uri里配置的URI(例如
+// "pkcs11:token=kubeadm;object=k8s-ca?pin-source=/etc/kubeadm/pin")选择并构造对应的Provider；
+// 空字符串返回fileProvider，保持历史默认行为不变。
+func ParseURI(uri string) (Provider, error) {
+	if uri == "" {
+		return fileProvider{}, nil
+	}
+
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, errors.Wrapf(err, "无法解析--ca-key-provider-uri %q", uri)
+	}
+
+	switch u.Scheme {
+	case "", "file":
+		return fileProvider{}, nil
+	case "pkcs11":
+		return newPKCS11Provider(uri)
+	case "kms":
+		return newKMSProvider(uri)
+	default:
+		return nil, errors.Errorf("未知的CA密钥Provider scheme %q", u.Scheme)
+	}
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterstatus
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/pkg/errors"
+)
+
+// get读取kubeadm维护的ClusterStatus单例。对象尚不存在时返回一个携带正确TypeMeta/Name、且两个
+// map都已经初始化好的空对象而不是错误，这样调用方可以直接往里面写，不必先判断是不是第一次创建。
+func get(ctx context.Context, client dynamic.Interface) (*ClusterStatus, error) {
+	obj, err := client.Resource(GroupVersionResource).Get(ctx, Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return &ClusterStatus{
+			TypeMeta:   metav1.TypeMeta{APIVersion: GroupVersionResource.GroupVersion().String(), Kind: "ClusterStatus"},
+			ObjectMeta: metav1.ObjectMeta{Name: Name},
+			Spec: ClusterStatusSpec{
+				APIEndpoints: map[string]APIEndpoint{},
+				EtcdMembers:  map[string]string{},
+			},
+		}, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "无法获取ClusterStatus")
+	}
+
+	status := &ClusterStatus{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, status); err != nil {
+		return nil, errors.Wrap(err, "无法解析ClusterStatus")
+	}
+	if status.Spec.APIEndpoints == nil {
+		status.Spec.APIEndpoints = map[string]APIEndpoint{}
+	}
+	if status.Spec.EtcdMembers == nil {
+		status.Spec.EtcdMembers = map[string]string{}
+	}
+	return status, nil
+}
+
+// save把status整体写回集群：ResourceVersion为空说明是首次创建，否则走Update。
+func save(ctx context.Context, client dynamic.Interface, status *ClusterStatus) error {
+	raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(status)
+	if err != nil {
+		return errors.Wrap(err, "无法序列化ClusterStatus")
+	}
+	obj := &unstructured.Unstructured{Object: raw}
+
+	resourceClient := client.Resource(GroupVersionResource)
+	if obj.GetResourceVersion() == "" {
+		_, err = resourceClient.Create(ctx, obj, metav1.CreateOptions{})
+	} else {
+		_, err = resourceClient.Update(ctx, obj, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return errors.Wrap(err, "无法写入ClusterStatus")
+	}
+	return nil
+}
+
+// UpsertMember把nodeName对应的API端点与etcd member ID写入ClusterStatus，供init/join的
+// mark-control-plane阶段调用。etcdMemberID为空时只更新APIEndpoints、不动EtcdMembers，用于外部
+// etcd场景下节点本身并不持有本地etcd成员的情况。
+//
+// get/修改/save这一整套读取-修改-写回循环被包在retry.RetryOnConflict里：多个控制平面节点可能在
+// init/join过程中并发登记自己，谁的Update先到谁赢，后到的一方会因ResourceVersion过期而收到409
+// 冲突，这时需要重新get一份最新的status再合并自己的修改，而不是直接把冲突原样返回给调用方。
+//
+// clusterstatuses.kubeadm.k8s.io这个CRD本身由集群管理员或其他组件安装，kubeadm自身不提供
+// 安装步骤(与本仓库里其他自定义资源的处理方式一致)。CRD尚未安装时，save的Create调用会以404
+// 失败；这里选择像RemoveMember对"对象不存在"那样宽容处理、只记录警告并跳过，而不是让整个
+// mark-control-plane阶段失败，因为登记ClusterStatus只是为了让未来的reset/join更准确地感知
+// 集群拓扑，并非init/join成功的必要条件。
+func UpsertMember(ctx context.Context, client dynamic.Interface, nodeName string, endpoint APIEndpoint, etcdMemberID string) error {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		status, err := get(ctx, client)
+		if err != nil {
+			return err
+		}
+
+		status.Spec.APIEndpoints[nodeName] = endpoint
+		if etcdMemberID != "" {
+			status.Spec.EtcdMembers[nodeName] = etcdMemberID
+		}
+
+		return save(ctx, client, status)
+	})
+	if apierrors.IsNotFound(errors.Cause(err)) {
+		fmt.Printf("[cluster-status] WARNING: ClusterStatus自定义资源(%s)在集群中不存在，跳过登记节点%q: %v\n",
+			GroupVersionResource, nodeName, err)
+		return nil
+	}
+	return err
+}
+
+// RemoveMember把nodeName从ClusterStatus.Spec.APIEndpoints与EtcdMembers中删除，供reset的
+// update-cluster-status阶段调用。ClusterStatus尚未创建过时(包括CRD本身未安装的情况)视为本来
+// 就不含这个节点，直接返回nil。
+//
+// 与UpsertMember一样，读取-修改-写回循环被包在retry.RetryOnConflict里，应对其他控制平面节点
+// 并发更新同一个ClusterStatus对象的情况。
+func RemoveMember(ctx context.Context, client dynamic.Interface, nodeName string) error {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		status, err := get(ctx, client)
+		if err != nil {
+			return err
+		}
+		if status.ObjectMeta.ResourceVersion == "" {
+			return nil
+		}
+
+		delete(status.Spec.APIEndpoints, nodeName)
+		delete(status.Spec.EtcdMembers, nodeName)
+
+		return save(ctx, client, status)
+	})
+	if apierrors.IsNotFound(errors.Cause(err)) {
+		return nil
+	}
+	return err
+}
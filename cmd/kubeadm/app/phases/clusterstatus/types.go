@@ -0,0 +1,57 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package clusterstatus维护一个kubeadm.k8s.io/v1 ClusterStatus自定义资源(cluster-scoped，
+// 集群里只有kubeadm-cluster-status这一个单例对象)，记录哪些节点还在提供API Server与本地etcd
+// 成员，取代v1beta3里被移除的ClusterStatus ConfigMap小节。init/join的mark-control-plane阶段
+// 调用UpsertMember登记本节点，reset的update-cluster-status阶段调用RemoveMember摘除本节点。
+package clusterstatus
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GroupVersionResource是ClusterStatus CR的GVR。
+var GroupVersionResource = schema.GroupVersionResource{
+	Group:    "kubeadm.k8s.io",
+	Version:  "v1",
+	Resource: "clusterstatuses",
+}
+
+// Name是kubeadm维护的ClusterStatus单例对象的名称；ClusterStatus是cluster-scoped的，集群里
+// 只存在这一份。
+const Name = "kubeadm-cluster-status"
+
+// APIEndpoint描述一个控制平面节点对外提供的API Server端点。
+type APIEndpoint struct {
+	AdvertiseAddress string `json:"advertiseAddress"`
+	BindPort         int32  `json:"bindPort"`
+}
+
+// ClusterStatusSpec记录当前仍在提供服务的控制平面节点与etcd成员，均以节点名为key。
+type ClusterStatusSpec struct {
+	APIEndpoints map[string]APIEndpoint `json:"apiEndpoints"`
+	EtcdMembers  map[string]string      `json:"etcdMembers"`
+}
+
+// ClusterStatus是kubeadm维护的自定义资源。
+type ClusterStatus struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ClusterStatusSpec `json:"spec"`
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cni
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// versionHandshakeTimeout是等待单个CNI插件二进制响应CNI_COMMAND=VERSION所允许的最长时长。
+const versionHandshakeTimeout = 5 * time.Second
+
+// DefaultConfDir是--cni-conf-dir未指定时使用的默认值，与kubelet自身的--cni-conf-dir默认值一致。
+const DefaultConfDir = "/etc/cni/net.d"
+
+// DefaultBinDir是--cni-bin-dir未指定时使用的默认值，与kubelet自身的--cni-bin-dir默认值一致。
+const DefaultBinDir = "/opt/cni/bin"
+
+// confList是.conflist文件的最小可解析子集：只取出我们需要用来定位插件二进制的plugins[].type。
+type confList struct {
+	CNIVersion string `json:"cniVersion"`
+	Plugins    []struct {
+		Type string `json:"type"`
+	} `json:"plugins"`
+}
+
+// singleConf是.conf文件的最小可解析子集，对应单个插件而非链式插件列表。
+type singleConf struct {
+	CNIVersion string `json:"cniVersion"`
+	Type       string `json:"type"`
+}
+
+// PluginProblem描述配置中引用的一个CNI插件在binDir下无法正常使用的原因。
+type PluginProblem struct {
+	// Type是配置文件中引用的插件类型，例如"calico"、"portmap"、"bridge"
+	Type string
+	// Reason是人类可读的问题描述，例如"在--cni-bin-dir下找不到可执行文件"
+	Reason string
+}
+
+// Status是ValidateSetup一次检查的结果。
+type Status struct {
+	// ConfigFile是在ConfDir下选中的配置文件的完整路径
+	ConfigFile string
+	// Problems列出配置中引用、但在BinDir下找不到或未能通过VERSION握手的插件
+	Problems []PluginProblem
+}
+
+// ValidateSetup检查confDir下是否存在至少一份可解析的*.conflist/*.conf网络配置，并对其中引用的每个
+// 插件类型，检查binDir下是否有同名可执行文件且能正确响应CNI_COMMAND=VERSION握手。
+//
+// 返回的error只在confDir本身不可用或其中不包含任何可解析的配置文件时出现——这类问题无论
+// --strict-cni与否都无法继续往下检查。单个插件二进制缺失或握手失败不会导致error，而是体现
+// 在返回的Status.Problems里，调用方可以根据--strict-cni决定是警告还是失败。
+func ValidateSetup(confDir, binDir string) (*Status, error) {
+	configFile, types, err := selectConfig(confDir)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &Status{ConfigFile: configFile}
+	for _, pluginType := range types {
+		if reason := checkPlugin(binDir, pluginType); reason != "" {
+			status.Problems = append(status.Problems, PluginProblem{Type: pluginType, Reason: reason})
+		}
+	}
+	return status, nil
+}
+
+// selectConfig在confDir中按文件名排序找到第一份可解析的*.conflist或*.conf文件，这与CNI库自身
+// 选取"默认网络"的规则一致，返回该文件路径及其中按顺序引用的插件类型列表。
+func selectConfig(confDir string) (string, []string, error) {
+	entries, err := os.ReadDir(confDir)
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "无法读取--cni-conf-dir %q", confDir)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		ext := filepath.Ext(entry.Name())
+		if ext == ".conflist" || ext == ".conf" {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		path := filepath.Join(confDir, name)
+		body, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		if filepath.Ext(name) == ".conflist" {
+			var list confList
+			if err := json.Unmarshal(body, &list); err != nil || len(list.Plugins) == 0 {
+				continue
+			}
+			types := make([]string, 0, len(list.Plugins))
+			for _, plugin := range list.Plugins {
+				types = append(types, plugin.Type)
+			}
+			return path, types, nil
+		}
+
+		var single singleConf
+		if err := json.Unmarshal(body, &single); err != nil || single.Type == "" {
+			continue
+		}
+		return path, []string{single.Type}, nil
+	}
+
+	return "", nil, errors.Errorf("--cni-conf-dir %q下没有找到可解析的*.conflist/*.conf文件", confDir)
+}
+
+// checkPlugin在binDir下查找pluginType对应的可执行文件，并通过CNI_COMMAND=VERSION握手确认它是
+// 一个可用的CNI插件；检查通过时返回空字符串，否则返回一句可读的失败原因。
+func checkPlugin(binDir, pluginType string) string {
+	path := filepath.Join(binDir, pluginType)
+	info, err := os.Stat(path)
+	if err != nil {
+		return "在--cni-bin-dir下找不到可执行文件"
+	}
+	if info.Mode()&0o111 == 0 {
+		return "文件存在但没有可执行权限"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), versionHandshakeTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Env = append(os.Environ(), "CNI_COMMAND=VERSION")
+	output, err := cmd.Output()
+	if err != nil {
+		return "未能正确响应CNI_COMMAND=VERSION握手"
+	}
+
+	var version struct {
+		CNIVersion        string   `json:"cniVersion"`
+		SupportedVersions []string `json:"supportedVersions"`
+	}
+	if err := json.Unmarshal(output, &version); err != nil {
+		return "CNI_COMMAND=VERSION握手返回的内容不是合法的版本信息JSON"
+	}
+	return ""
+}
@@ -18,17 +18,81 @@ package kubelet
 
 import (
 	"fmt"
+	"net"
+	"net/http"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
 
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/kubernetes/cmd/kubeadm/app/util/initsystem"
+
+	"github.com/pkg/errors"
+)
+
+// defaultKubeletHealthzBindAddress和defaultKubeletHealthzPort是kubelet自身--healthz-bind-address
+// 和--healthz-port标志的默认值；kubeadm尚未把KubeletConfiguration里的这两个字段读出来传给这里，
+// 所以暂时按kubelet的默认值轮询，未来有了访问KubeletConfiguration的入口后可以把真实值传进来。
+const (
+	defaultKubeletHealthzBindAddress = "127.0.0.1"
+	defaultKubeletHealthzPort        = 10248
 )
 
-// TryStartKubelet attempts to bring up kubelet service
-func TryStartKubelet() {
+// kubeletHealthzBackoff是等待kubelet healthz端点返回200的指数退避策略，总耗时上限约4分钟，
+// 与kubelet自身轮询静态Pod清单目录的20秒周期处于同一数量级。
+var kubeletHealthzBackoff = wait.Backoff{
+	Duration: 500 * time.Millisecond,
+	Factor:   1.5,
+	Jitter:   0.1,
+	Steps:    24,
+	Cap:      15 * time.Second,
+}
+
+// KubeletHealthzErrorKind区分TryStartKubelet/TryRestartKubelet在等待kubelet就绪时可能遇到的
+// 几类失败原因，便于上层阶段(如等待控制平面Pod就绪)给出更准确的诊断信息，而不是统一报超时。
+type KubeletHealthzErrorKind string
+
+const (
+	// KubeletHealthzErrorInitSystem表示本机没有受支持的init系统，或者(重新)启动kubelet服务本身
+	// 就失败了，这种情况下kubeadm从未真正等到healthz轮询这一步。
+	KubeletHealthzErrorInitSystem KubeletHealthzErrorKind = "init-system"
+	// KubeletHealthzErrorConnectionRefused表示一直连接不上healthz端口，通常说明kubelet进程
+	// 启动后很快又崩溃退出了。
+	KubeletHealthzErrorConnectionRefused KubeletHealthzErrorKind = "connection-refused"
+	// KubeletHealthzErrorUnhealthy表示healthz端口一直能连上，但反复返回非200状态码，说明kubelet
+	// 进程还活着，但认为自己没有就绪。
+	KubeletHealthzErrorUnhealthy KubeletHealthzErrorKind = "unhealthy"
+)
+
+// KubeletHealthzError是TryStartKubelet/TryRestartKubelet等待kubelet healthz端点就绪失败时返回的
+// 错误类型，附带一段kubelet自身日志用于诊断。
+type KubeletHealthzError struct {
+	Kind    KubeletHealthzErrorKind
+	Err     error
+	LogTail string
+}
+
+func (e *KubeletHealthzError) Error() string {
+	msg := fmt.Sprintf("等待kubelet healthz端点就绪失败(%s): %v", e.Kind, e.Err)
+	if e.LogTail != "" {
+		msg = fmt.Sprintf("%s\n最近的kubelet日志:\n%s", msg, e.LogTail)
+	}
+	return msg
+}
+
+func (e *KubeletHealthzError) Unwrap() error {
+	return e.Err
+}
+
+// TryStartKubelet attempts to bring up kubelet service, then waits for its healthz endpoint to
+// report 200 OK before returning.
+func TryStartKubelet() error {
 	// If we notice that the kubelet service is inactive, try to start it
 	initSystem, err := initsystem.GetInitSystem()
 	if err != nil {
 		fmt.Println("[kubelet-start] no supported init system detected, won't make sure the kubelet is running properly.")
-		return
+		return &KubeletHealthzError{Kind: KubeletHealthzErrorInitSystem, Err: err}
 	}
 
 	if !initSystem.ServiceExists("kubelet") {
@@ -39,7 +103,10 @@ func TryStartKubelet() {
 	if err := initSystem.ServiceRestart("kubelet"); err != nil {
 		fmt.Printf("[kubelet-start] WARNING: unable to start the kubelet service: [%v]\n", err)
 		fmt.Printf("[kubelet-start] Please ensure kubelet is reloaded and running manually.\n")
+		return &KubeletHealthzError{Kind: KubeletHealthzErrorInitSystem, Err: err}
 	}
+
+	return waitForKubeletHealthz()
 }
 
 // TryStopKubelet 试图暂时关闭kubelet服务
@@ -61,13 +128,14 @@ func TryStopKubelet() {
 	}
 }
 
-// TryRestartKubelet attempts to restart the kubelet service
-func TryRestartKubelet() {
+// TryRestartKubelet attempts to restart the kubelet service, then waits for its healthz endpoint
+// to report 200 OK before returning.
+func TryRestartKubelet() error {
 	// If we notice that the kubelet service is inactive, try to start it
 	initSystem, err := initsystem.GetInitSystem()
 	if err != nil {
 		fmt.Println("[kubelet-start] no supported init system detected, won't make sure the kubelet not running for a short period of time while setting up configuration for it.")
-		return
+		return &KubeletHealthzError{Kind: KubeletHealthzErrorInitSystem, Err: err}
 	}
 
 	if !initSystem.ServiceExists("kubelet") {
@@ -77,5 +145,76 @@ func TryRestartKubelet() {
 	// This runs "systemctl daemon-reload && systemctl stop kubelet"
 	if err := initSystem.ServiceRestart("kubelet"); err != nil {
 		fmt.Printf("[kubelet-start] WARNING: unable to restart the kubelet service momentarily: [%v]\n", err)
+		return &KubeletHealthzError{Kind: KubeletHealthzErrorInitSystem, Err: err}
+	}
+
+	return waitForKubeletHealthz()
+}
+
+// waitForKubeletHealthz在ServiceRestart返回之后，轮询kubelet自身的healthz端点直到它返回200 OK，
+// 或者超出kubeletHealthzBackoff规定的总时长。此前TryStartKubelet/TryRestartKubelet在
+// ServiceRestart成功后就直接返回，kubelet是否真的起来了完全交给后续阶段(如等待控制平面清单)去
+// 超时探测，报错信息对用户很不友好；这里提前把"kubelet起来了没有"这件事盯紧，并区分是一直连不上
+// (大概率是kubelet崩溃了)还是连得上但不健康。
+func waitForKubeletHealthz() error {
+	url := fmt.Sprintf("http://%s:%d/healthz", defaultKubeletHealthzBindAddress, defaultKubeletHealthzPort)
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	var lastErr error
+	var lastStatus int
+	pollErr := wait.ExponentialBackoff(kubeletHealthzBackoff, func() (bool, error) {
+		resp, err := client.Get(url)
+		if err != nil {
+			lastErr = err
+			return false, nil
+		}
+		defer resp.Body.Close()
+		lastStatus = resp.StatusCode
+		return resp.StatusCode == http.StatusOK, nil
+	})
+	if pollErr == nil {
+		return nil
+	}
+
+	kind := KubeletHealthzErrorUnhealthy
+	reportErr := lastErr
+	if reportErr == nil {
+		reportErr = errors.Errorf("healthz端点最近一次返回了非200状态码: %d", lastStatus)
+	} else if isConnRefused(reportErr) {
+		kind = KubeletHealthzErrorConnectionRefused
+	}
+
+	return &KubeletHealthzError{
+		Kind:    kind,
+		Err:     reportErr,
+		LogTail: tailKubeletLog(),
+	}
+}
+
+// isConnRefused粗略判断err是否属于"连接被拒绝"这一类，用来区分kubelet进程崩溃退出(拒绝连接)和
+// kubelet虽然活着但尚未就绪(能连上、只是没有返回200)这两种情况。
+func isConnRefused(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return strings.Contains(opErr.Err.Error(), "connection refused")
+	}
+	return strings.Contains(err.Error(), "connection refused")
+}
+
+// tailKubeletLog尽力抓取kubelet最近的一段日志，供KubeletHealthzError附带展示，帮助用户判断kubelet
+// 究竟是没起来、崩溃了、还是起来了但没就绪。抓取失败(例如所在发行版没有journalctl)时静默返回空
+// 字符串，不影响KubeletHealthzError本身的返回。
+func tailKubeletLog() string {
+	var out []byte
+	var err error
+	switch runtime.GOOS {
+	case "windows":
+		out, err = exec.Command("wevtutil", "qe", "System", "/q:*[System[Provider[@Name='kubelet']]]", "/c:40", "/rd:true", "/f:text").CombinedOutput()
+	default:
+		out, err = exec.Command("journalctl", "-u", "kubelet", "-n", "40", "--no-pager").CombinedOutput()
+	}
+	if err != nil {
+		return ""
 	}
+	return strings.TrimSpace(string(out))
 }
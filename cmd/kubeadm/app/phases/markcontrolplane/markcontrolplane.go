@@ -17,33 +17,33 @@ limitations under the License.
 package markcontrolplane
 
 import (
+	"context"
 	"fmt"
 
-	"k8s.io/kubernetes/cmd/kubeadm/app/constants"
+	"k8s.io/kubernetes/cmd/kubeadm/app/phases/clusterstatus"
+	"k8s.io/kubernetes/cmd/kubeadm/app/phases/markcontrolplane/nodepolicy"
 	"k8s.io/kubernetes/cmd/kubeadm/app/util/apiclient"
 
 	"k8s.io/api/core/v1"
 	clientset "k8s.io/client-go/kubernetes"
-)
 
-var labelsToAdd = []string{
-	// TODO: remove this label:
-	// https://github.com/kubernetes/kubeadm/issues/2200
-	constants.LabelNodeRoleOldControlPlane,
-	constants.LabelNodeRoleControlPlane,
-	constants.LabelExcludeFromExternalLB,
-}
+	"k8s.io/client-go/dynamic"
 
-// MarkControlPlane 污染控制平面并设置控制平面标签
-func MarkControlPlane(client clientset.Interface, controlPlaneName string, taints []v1.Taint) error {
-	// TODO:删除此“已弃用”修改并直接传递“标签加载”:
-	// https://github.com/kubernetes/kubeadm/issues/2200
-	labels := make([]string, len(labelsToAdd))
-	copy(labels, labelsToAdd)
-	labels[0] = constants.LabelNodeRoleOldControlPlane + "(deprecated)"
+	"github.com/pkg/errors"
+)
 
-	fmt.Printf("[mark-control-plane] 通过添加标签将节点%s标记为控制平面: %v\n",
-		controlPlaneName, labels)
+// MarkControlPlane 污染控制平面并设置控制平面标签；具体加哪些标签、是否追加额外污点由
+// policyName指定的nodepolicy.NodeMarkPolicy决定。policyName通常来自kubeadmapi.
+// InitConfiguration.NodeRegistration.NodePolicy，留空或引用未注册的名称时回退到
+// nodepolicy.DefaultPolicyName，行为与引入该机制之前完全一致。
+//
+// 打完标签/污点之后，如果dynamicClient非nil，还会把本节点的endpoint与etcdMemberID写入
+// ClusterStatus自定义资源(见clusterstatus包)；etcdMemberID为空表示本节点不持有本地etcd成员
+// (例如外部etcd模式)。dynamicClient为nil时跳过这一步，与历史上不存在ClusterStatus的行为一致。
+func MarkControlPlane(ctx context.Context, client clientset.Interface, dynamicClient dynamic.Interface, controlPlaneName string, taints []v1.Taint, policyName string, endpoint clusterstatus.APIEndpoint, etcdMemberID string) error {
+	policy := nodepolicy.Get(policyName)
+
+	fmt.Printf("[mark-control-plane] 按策略%q将节点%s标记为控制平面\n", policyName, controlPlaneName)
 
 	if len(taints) > 0 {
 		taintStrs := []string{}
@@ -53,9 +53,20 @@ func MarkControlPlane(client clientset.Interface, controlPlaneName string, taint
 		fmt.Printf("[mark-control-plane] 通过添加污点将节点%s标记为控制平面 %v\n", controlPlaneName, taintStrs)
 	}
 
-	return apiclient.PatchNode(client, controlPlaneName, func(n *v1.Node) {
+	if err := apiclient.PatchNode(client, controlPlaneName, func(n *v1.Node) {
 		markControlPlaneNode(n, taints)
-	})
+		policy.Apply(n)
+	}); err != nil {
+		return err
+	}
+
+	if dynamicClient == nil {
+		return nil
+	}
+	if err := clusterstatus.UpsertMember(ctx, dynamicClient, controlPlaneName, endpoint, etcdMemberID); err != nil {
+		return errors.Wrap(err, "无法在ClusterStatus中登记本节点")
+	}
+	return nil
 }
 
 func taintExists(taint v1.Taint, taints []v1.Taint) bool {
@@ -69,10 +80,6 @@ func taintExists(taint v1.Taint, taints []v1.Taint) bool {
 }
 
 func markControlPlaneNode(n *v1.Node, taints []v1.Taint) {
-	for _, label := range labelsToAdd {
-		n.ObjectMeta.Labels[label] = ""
-	}
-
 	for _, nt := range n.Spec.Taints {
 		if !taintExists(nt, taints) {
 			taints = append(taints, nt)
@@ -0,0 +1,112 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package nodepolicy定义了markcontrolplane在给节点打标签/加污点时使用的可插拔策略，替代此前
+// 写死在markcontrolplane.go里的labelsToAdd列表，让运维方可以按节点角色(worker/edge/gpu等)声明
+// 额外标签、自定义污点与标签删除规则，而不必修改kubeadm本身。
+package nodepolicy
+
+import (
+	"k8s.io/kubernetes/cmd/kubeadm/app/constants"
+
+	"k8s.io/api/core/v1"
+)
+
+// NodeMarkPolicy是标记控制平面节点时对节点对象做标签/污点变更的策略接口。MarkControlPlane在
+// apiclient.PatchNode的回调内、完成通用污点合并之后调用Apply，策略可以继续增删Labels、
+// Annotations与Taints。
+type NodeMarkPolicy interface {
+	Apply(n *v1.Node)
+}
+
+// DefaultPolicyName是policyName为空、或引用了未注册名称时回退使用的策略名称。
+const DefaultPolicyName = "Default"
+
+// HighAvailabilityControlPlaneName是HighAvailabilityControlPlane策略的注册名。
+const HighAvailabilityControlPlaneName = "HighAvailabilityControlPlane"
+
+// labelExcludeFromDisruption标记节点不应被自愈类控制器同时驱逐，供多控制面高可用场景使用。
+const labelExcludeFromDisruption = "node.kubernetes.io/exclude-disruption"
+
+var registry = map[string]NodeMarkPolicy{
+	DefaultPolicyName:                defaultPolicy{},
+	HighAvailabilityControlPlaneName: HighAvailabilityControlPlane{},
+}
+
+// Register以name为键登记一个策略实现，重复注册同一名称会覆盖之前的实现。运维方可以在自己的
+// kubeadm构建或init钩子里调用Register登记针对zone、instance-type等自定义标签的策略，再通过
+// kubeadmapi.InitConfiguration.NodeRegistration.NodePolicy按名称引用。
+func Register(name string, policy NodeMarkPolicy) {
+	registry[name] = policy
+}
+
+// Get按名称查找已注册的策略；name为空或未注册时返回DefaultPolicyName对应的策略，调用方不必
+// 单独处理"未配置"这种情况。
+func Get(name string) NodeMarkPolicy {
+	if policy, ok := registry[name]; ok {
+		return policy
+	}
+	return registry[DefaultPolicyName]
+}
+
+// defaultPolicy复现引入NodeMarkPolicy之前markControlPlaneNode里的标签行为：添加控制平面角色
+// 标签与exclude-from-external-LB标签，用于保持旧版本的默认行为。
+type defaultPolicy struct{}
+
+var labelsToAdd = []string{
+	// TODO: remove this label:
+	// https://github.com/kubernetes/kubeadm/issues/2200
+	constants.LabelNodeRoleOldControlPlane,
+	constants.LabelNodeRoleControlPlane,
+	constants.LabelExcludeFromExternalLB,
+}
+
+func (defaultPolicy) Apply(n *v1.Node) {
+	for _, label := range labelsToAdd {
+		n.ObjectMeta.Labels[label] = ""
+	}
+}
+
+// HighAvailabilityControlPlane在defaultPolicy的基础上，额外为节点加上exclude-disruption标签，
+// 并可选地追加一个NoExecute污点，避免自愈控制器在多控制面场景下一次性驱逐所有控制平面节点。
+// TaintKey留空时不追加该污点。TolerationSeconds不写入节点本身(v1.Taint没有这个字段)，而是
+// 供调用方在为需要容忍该污点的工作负载构造对应Toleration时读取，两边保持一致。
+type HighAvailabilityControlPlane struct {
+	TaintKey          string
+	TaintValue        string
+	TolerationSeconds *int64
+}
+
+func (p HighAvailabilityControlPlane) Apply(n *v1.Node) {
+	defaultPolicy{}.Apply(n)
+	n.ObjectMeta.Labels[labelExcludeFromDisruption] = ""
+
+	if p.TaintKey == "" {
+		return
+	}
+
+	taint := v1.Taint{
+		Key:    p.TaintKey,
+		Value:  p.TaintValue,
+		Effect: v1.TaintEffectNoExecute,
+	}
+	for _, existing := range n.Spec.Taints {
+		if existing.Key == taint.Key && existing.Value == taint.Value && existing.Effect == taint.Effect {
+			return
+		}
+	}
+	n.Spec.Taints = append(n.Spec.Taints, taint)
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/pkg/errors"
+)
+
+// Checker是一次预检检查的最小契约：Name用于在`--ignore-preflight-errors`中引用该检查，
+// Check执行实际的检查逻辑，分别返回非致命的警告和致命的错误。
+type Checker interface {
+	Name() string
+	Check() (warnings, errorList []error)
+}
+
+// RunChecks依次执行checks中的每一项检查，把warnings打印到ww，并把被忽略(ignorePreflightErrors
+// 中按名称或"all"命中)的错误降级为警告打印，其余错误汇总后作为一个error返回。这是preflight包中
+// 内建检查与ExternalChecker共用的统一聚合入口，保证无论检查来自哪里，输出格式都是一致的。
+func RunChecks(checks []Checker, ww io.Writer, ignorePreflightErrors sets.String) error {
+	var errsBuffer bytes.Buffer
+
+	for _, c := range checks {
+		name := strings.ToLower(c.Name())
+		warnings, errs := c.Check()
+
+		for _, w := range warnings {
+			fmt.Fprintf(ww, "\t[WARNING %s]: %v\n", c.Name(), w)
+		}
+
+		if len(errs) == 0 {
+			continue
+		}
+
+		if ignorePreflightErrors.Has(name) || ignorePreflightErrors.Has("all") {
+			for _, e := range errs {
+				fmt.Fprintf(ww, "\t[WARNING %s]: %v\n", c.Name(), e)
+			}
+			continue
+		}
+
+		for _, e := range errs {
+			errsBuffer.WriteString(fmt.Sprintf("\t[ERROR %s]: %v\n", c.Name(), e))
+		}
+	}
+
+	if errsBuffer.Len() > 0 {
+		return errors.Errorf("[preflight] 部分检查未通过:\n%s请使用 --ignore-preflight-errors=<检查名称> 忽略", errsBuffer.String())
+	}
+	return nil
+}
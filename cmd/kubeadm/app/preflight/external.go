@@ -0,0 +1,308 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	kubeadmapi "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultPreflightPluginDir是扫描外部预检插件与声明式检查的默认目录。
+const DefaultPreflightPluginDir = "/etc/kubernetes/preflight.d/"
+
+// ExternalChecker是来自preflight.d目录的检查（无论是可执行插件还是声明式YAML检查）的统一接口。
+// 除了满足Checker之外，它还暴露Source，用于在日志中标明某次检查来自哪个文件，便于排查配置错误。
+type ExternalChecker interface {
+	Checker
+	// Source返回该检查的来源文件路径（插件可执行文件或声明式YAML文件）。
+	Source() string
+}
+
+// pluginResult是外部插件在标准输出上必须打印的JSON契约。
+type pluginResult struct {
+	Name        string `json:"name"`
+	Status      string `json:"status"`
+	Message     string `json:"message"`
+	Remediation string `json:"remediation"`
+}
+
+// DiscoverExternalChecks扫描dir，把其中每一个具有可执行权限的文件视为一个外部插件检查，
+// 把每一个.yaml/.yml文件视为一组声明式检查。dir不存在时视为没有配置外部检查，返回空结果而非错误。
+func DiscoverExternalChecks(dir string, cfg *kubeadmapi.InitConfiguration) ([]ExternalChecker, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "无法扫描预检插件目录 %q", dir)
+	}
+
+	var checks []ExternalChecker
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+
+		if strings.HasSuffix(entry.Name(), ".yaml") || strings.HasSuffix(entry.Name(), ".yml") {
+			declared, err := loadDeclarativeChecks(path)
+			if err != nil {
+				return nil, err
+			}
+			checks = append(checks, declared...)
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil, errors.Wrapf(err, "无法获取 %q 的文件信息", path)
+		}
+		if info.Mode()&0111 == 0 {
+			// 既不是声明式YAML检查，也没有可执行权限，视为不相关的文件（例如README），跳过。
+			continue
+		}
+		checks = append(checks, &pluginChecker{path: path, cfg: cfg})
+	}
+	return checks, nil
+}
+
+// pluginChecker通过执行外部二进制实现ExternalChecker：把cfg以JSON形式写入其标准输入，
+// 并按pluginResult契约解析其标准输出。
+type pluginChecker struct {
+	path string
+	cfg  *kubeadmapi.InitConfiguration
+}
+
+// Name返回插件文件名（不含扩展名），作为`--ignore-preflight-errors`可以引用的稳定检查名称。
+func (c *pluginChecker) Name() string {
+	base := filepath.Base(c.path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// Source返回插件可执行文件的路径。
+func (c *pluginChecker) Source() string {
+	return c.path
+}
+
+// Check执行插件并解析其输出；status=fail或插件以非零状态退出都会产生致命错误，status=warn产生警告。
+func (c *pluginChecker) Check() (warnings, errorList []error) {
+	stdin, err := json.Marshal(c.cfg)
+	if err != nil {
+		errorList = append(errorList, errors.Wrapf(err, "无法序列化InitConfiguration供插件 %q 使用", c.path))
+		return
+	}
+
+	cmd := exec.Command(c.path)
+	cmd.Stdin = bytes.NewReader(stdin)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	runErr := cmd.Run()
+
+	var result pluginResult
+	if err := json.Unmarshal(bytes.TrimSpace(stdout.Bytes()), &result); err != nil {
+		errorList = append(errorList, errors.Wrapf(err, "预检插件 %q 的输出不符合JSON契约: %q", c.path, stdout.String()))
+		return
+	}
+
+	msg := result.Message
+	if result.Remediation != "" {
+		msg = fmt.Sprintf("%s (修复建议: %s)", msg, result.Remediation)
+	}
+
+	switch result.Status {
+	case "warn":
+		warnings = append(warnings, errors.New(msg))
+	case "fail":
+		errorList = append(errorList, errors.New(msg))
+	case "pass":
+		if runErr != nil {
+			errorList = append(errorList, errors.Wrapf(runErr, "插件 %q 报告status=pass，但以非零状态退出", c.path))
+		}
+	default:
+		errorList = append(errorList, errors.Errorf("插件 %q 返回了无法识别的status %q", c.path, result.Status))
+	}
+	return
+}
+
+// declarativeCheckFile是preflight.d目录中一个YAML文件的顶层结构，其中可以声明任意多个检查。
+type declarativeCheckFile struct {
+	Checks []declarativeCheckSpec `json:"checks"`
+}
+
+// declarativeCheckSpec是单个声明式检查的定义；恰好应该填写其中一种检查条件。
+type declarativeCheckSpec struct {
+	Name               string               `json:"name"`
+	Remediation        string               `json:"remediation,omitempty"`
+	FileExists         string               `json:"fileExists,omitempty"`
+	SysctlEquals       *sysctlEqualsSpec    `json:"sysctlEquals,omitempty"`
+	KernelModuleLoaded string               `json:"kernelModuleLoaded,omitempty"`
+	PortFree           int                  `json:"portFree,omitempty"`
+	CommandExitCode    *commandExitCodeSpec `json:"commandExitCode,omitempty"`
+}
+
+type sysctlEqualsSpec struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type commandExitCodeSpec struct {
+	Command string   `json:"command"`
+	Args    []string `json:"args,omitempty"`
+	Expect  int      `json:"expect"`
+}
+
+// loadDeclarativeChecks解析path中声明的所有检查。
+func loadDeclarativeChecks(path string) ([]ExternalChecker, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "无法读取声明式检查文件 %q", path)
+	}
+
+	var file declarativeCheckFile
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return nil, errors.Wrapf(err, "无法解析声明式检查文件 %q", path)
+	}
+
+	checks := make([]ExternalChecker, 0, len(file.Checks))
+	for _, spec := range file.Checks {
+		checks = append(checks, &declarativeChecker{spec: spec, source: path})
+	}
+	return checks, nil
+}
+
+// declarativeChecker实现preflight.d目录下一小套无需编写可执行文件就能声明的检查类型：
+// 文件是否存在、sysctl值是否符合预期、内核模块是否已加载、端口是否空闲、命令的退出码是否符合预期。
+type declarativeChecker struct {
+	spec   declarativeCheckSpec
+	source string
+}
+
+func (c *declarativeChecker) Name() string {
+	return c.spec.Name
+}
+
+func (c *declarativeChecker) Source() string {
+	return c.source
+}
+
+func (c *declarativeChecker) Check() (warnings, errorList []error) {
+	switch {
+	case c.spec.FileExists != "":
+		if _, err := os.Stat(c.spec.FileExists); err != nil {
+			errorList = append(errorList, c.fail("文件 %q 不存在: %v", c.spec.FileExists, err))
+		}
+	case c.spec.SysctlEquals != nil:
+		got, err := readSysctl(c.spec.SysctlEquals.Key)
+		if err != nil {
+			errorList = append(errorList, c.fail("无法读取sysctl %q: %v", c.spec.SysctlEquals.Key, err))
+		} else if got != c.spec.SysctlEquals.Value {
+			errorList = append(errorList, c.fail("sysctl %q 的值是 %q，期望 %q", c.spec.SysctlEquals.Key, got, c.spec.SysctlEquals.Value))
+		}
+	case c.spec.KernelModuleLoaded != "":
+		loaded, err := isKernelModuleLoaded(c.spec.KernelModuleLoaded)
+		if err != nil {
+			errorList = append(errorList, c.fail("无法检查内核模块 %q: %v", c.spec.KernelModuleLoaded, err))
+		} else if !loaded {
+			errorList = append(errorList, c.fail("内核模块 %q 未加载", c.spec.KernelModuleLoaded))
+		}
+	case c.spec.PortFree != 0:
+		if err := checkPortFree(c.spec.PortFree); err != nil {
+			errorList = append(errorList, c.fail("端口 %d 不可用: %v", c.spec.PortFree, err))
+		}
+	case c.spec.CommandExitCode != nil:
+		if err := checkCommandExitCode(c.spec.CommandExitCode); err != nil {
+			errorList = append(errorList, c.fail("命令检查未通过: %v", err))
+		}
+	default:
+		errorList = append(errorList, errors.Errorf("声明式检查 %q (来自 %q) 未指定任何检查条件", c.spec.Name, c.source))
+	}
+	return
+}
+
+// fail把格式化后的错误消息与该检查的remediation提示拼接起来。
+func (c *declarativeChecker) fail(format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	if c.spec.Remediation != "" {
+		msg = fmt.Sprintf("%s (修复建议: %s)", msg, c.spec.Remediation)
+	}
+	return errors.New(msg)
+}
+
+// readSysctl通过/proc/sys读取内核参数的当前值。
+func readSysctl(key string) (string, error) {
+	path := filepath.Join("/proc/sys", strings.ReplaceAll(key, ".", "/"))
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+// isKernelModuleLoaded通过/proc/modules判断给定内核模块当前是否已加载。
+func isKernelModuleLoaded(name string) (bool, error) {
+	content, err := os.ReadFile("/proc/modules")
+	if err != nil {
+		return false, err
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) > 0 && fields[0] == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// checkPortFree尝试在所有接口上监听给定的TCP端口，以此探测该端口当前是否空闲。
+func checkPortFree(port int) error {
+	ln, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return err
+	}
+	return ln.Close()
+}
+
+// checkCommandExitCode运行spec描述的命令，并检查其退出码是否与spec.Expect相符。
+func checkCommandExitCode(spec *commandExitCodeSpec) error {
+	cmd := exec.Command(spec.Command, spec.Args...)
+	err := cmd.Run()
+
+	exitCode := 0
+	if err != nil {
+		exitErr, ok := err.(*exec.ExitError)
+		if !ok {
+			return err
+		}
+		exitCode = exitErr.ExitCode()
+	}
+
+	if exitCode != spec.Expect {
+		return errors.Errorf("命令 %q 的退出码是 %d，期望 %d", spec.Command, exitCode, spec.Expect)
+	}
+	return nil
+}
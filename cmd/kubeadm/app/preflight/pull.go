@@ -0,0 +1,238 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	kubeadmapi "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm"
+	kubeadmconstants "k8s.io/kubernetes/cmd/kubeadm/app/constants"
+	utilruntime "k8s.io/kubernetes/cmd/kubeadm/app/util/runtime"
+
+	errorsutil "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+)
+
+// maxPullBackoff是单张镜像重试拉取时允许等待的退避时长上限。
+const maxPullBackoff = 30 * time.Second
+
+// PullOptions配置RunPullImagesCheckWithOptions拉取控制平面镜像时的并发度、重试策略、超时与进度输出。
+type PullOptions struct {
+	// Concurrency是同时进行的镜像拉取worker数量，小于等于0时按1处理。
+	Concurrency int
+	// MaxRetries是单张镜像允许的最大重试次数（不含首次尝试）。
+	MaxRetries int
+	// BackoffBase是指数退避的基础时长：第n次重试等待 BackoffBase*2^(n-1)（再加上±20%的抖动），
+	// 并以maxPullBackoff为上限。
+	BackoffBase time.Duration
+	// Timeout是本次预拉取整体允许耗费的时长，小于等于0表示不设上限。一旦超时，尚未完成的镜像会
+	// 连同已经失败的镜像一起计入返回的聚合错误，但不会中止仍在后台运行的worker。
+	Timeout time.Duration
+	// ProgressWriter接收每张镜像拉取完成后的一行进度日志；为nil时不打印进度。
+	ProgressWriter io.Writer
+}
+
+// DefaultPullOptions返回RunPullImagesCheckWithOptions在未显式配置时使用的默认值。
+func DefaultPullOptions() PullOptions {
+	return PullOptions{
+		Concurrency: 4,
+		MaxRetries:  5,
+		BackoffBase: time.Second,
+		Timeout:     15 * time.Minute,
+	}
+}
+
+// RunPullImagesCheckWithOptions通过runtime并发拉取images中列出的全部镜像，并按PullOptions控制并发度、
+// 失败重试与进度输出——这比逐张镜像串行且不重试地拉取更能容忍网络抖动或镜像仓库的短暂不可用，避免
+// kubeadm init静默卡住数分钟。
+func RunPullImagesCheckWithOptions(runtime utilruntime.ContainerRuntime, images []string, opts PullOptions) error {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+
+	jobs := make(chan string)
+	results := make(chan imagePullResult, len(images))
+	var wg sync.WaitGroup
+
+	for i := 0; i < opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for image := range jobs {
+				results <- imagePullResult{image: image, err: pullImageWithRetry(runtime, image, opts)}
+			}
+		}()
+	}
+
+	go func() {
+		for _, image := range images {
+			jobs <- image
+		}
+		close(jobs)
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var deadline <-chan time.Time
+	if opts.Timeout > 0 {
+		deadline = time.After(opts.Timeout)
+	}
+
+	failures := []error{}
+	completed := 0
+collectLoop:
+	for completed < len(images) {
+		select {
+		case res := <-results:
+			completed++
+			if res.err != nil {
+				failures = append(failures, res.err)
+			}
+		case <-deadline:
+			failures = append(failures, errors.Errorf(
+				"预拉取镜像超时 (已等待 %v，仍有 %d/%d 张镜像未完成)", opts.Timeout, len(images)-completed, len(images)))
+			break collectLoop
+		}
+	}
+
+	if len(failures) > 0 {
+		return errorsutil.NewAggregate(failures)
+	}
+	return nil
+}
+
+// imagePullResult记录一张镜像的拉取结果，err为nil代表拉取成功。
+type imagePullResult struct {
+	image string
+	err   error
+}
+
+// pullImageWithRetry通过runtime拉取单张镜像，在瞬时性错误(镜像拉取超时、网络超时、镜像仓库5xx)上按
+// 指数退避重试，直至成功或用尽opts.MaxRetries次重试；失败时返回的错误中带有镜像仓库地址与完整镜像名，
+// 便于定位到底是哪张镜像、从哪个仓库拉取失败。
+func pullImageWithRetry(runtime utilruntime.ContainerRuntime, image string, opts PullOptions) error {
+	start := time.Now()
+	var lastErr error
+	for attempt := 0; attempt <= opts.MaxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(pullBackoffDuration(opts.BackoffBase, attempt))
+		}
+
+		err := runtime.PullImage(image)
+		if err == nil {
+			if opts.ProgressWriter != nil {
+				fmt.Fprintf(opts.ProgressWriter, "[image-prepull] 已拉取 %d/%d %s (%s)\n",
+					attempt+1, opts.MaxRetries+1, image, time.Since(start).Round(100*time.Millisecond))
+			}
+			return nil
+		}
+
+		lastErr = err
+		if !isTransientPullError(err) {
+			break
+		}
+		klog.V(3).Infof("[image-prepull] 拉取镜像 %s 失败 (第 %d 次尝试): %v", image, attempt+1, err)
+	}
+	return errors.Wrapf(lastErr, "无法从镜像仓库 %s 拉取镜像 %s", imageRegistry(image), image)
+}
+
+// pullBackoffDuration计算第attempt次重试前应等待的时长：BackoffBase*2^(attempt-1)，叠加±20%的抖动，
+// 并以maxPullBackoff为上限。
+func pullBackoffDuration(base time.Duration, attempt int) time.Duration {
+	backoff := base * time.Duration(int64(1)<<uint(attempt-1))
+	if backoff > maxPullBackoff {
+		backoff = maxPullBackoff
+	}
+	jitter := 0.8 + 0.4*rand.Float64()
+	return time.Duration(float64(backoff) * jitter)
+}
+
+// isTransientPullError判断一次拉取失败是否值得重试。优先从err中解析出CRI gRPC调用本身携带的
+// Status Code——GRPCCRIRuntime.PullImage透传的就是这个错误，能准确区分Unavailable/
+// DeadlineExceeded/ResourceExhausted/Aborted/Internal这类通常代表仓库侧瞬时故障的状态码，与
+// NotFound/PermissionDenied/InvalidArgument这类重试了也不会成功的永久性错误。对于走crictl/docker
+// CLI shell-out的CRIRuntime/DockerRuntime，CRI没有结构化的错误可用，只能退化成匹配命令输出里
+// 少数明确的瞬时性关键词；镜像名本身常见的sha256摘要会和裸"50"这类数字子串撞上，因此不再做这种
+// 宽松匹配。
+func isTransientPullError(err error) bool {
+	if code, ok := grpcStatusCode(err); ok {
+		switch code {
+		case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted, codes.Internal:
+			return true
+		default:
+			return false
+		}
+	}
+
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "i/o timeout") ||
+		strings.Contains(msg, "timeout") ||
+		strings.Contains(msg, "connection reset") ||
+		strings.Contains(msg, "connection refused")
+}
+
+// grpcStatusCode尝试把err解析成一次gRPC调用返回的Status Code；err并非由gRPC错误包装而来
+// (例如crictl/docker CLI shell-out返回的纯文本错误)时ok为false。
+func grpcStatusCode(err error) (codes.Code, bool) {
+	s, ok := status.FromError(errors.Cause(err))
+	if !ok {
+		return codes.OK, false
+	}
+	return s.Code(), true
+}
+
+// imageRegistry从形如"registry.k8s.io/kube-apiserver:v1.26.0"的镜像名中解析出仓库地址，用于在拉取失败时
+// 定位到底是哪个镜像仓库不可达；无法识别出独立仓库地址前缀的镜像（例如未加前缀的library镜像）一律归为
+// 默认的docker.io。
+func imageRegistry(image string) string {
+	parts := strings.SplitN(image, "/", 2)
+	if len(parts) == 2 && (strings.Contains(parts[0], ".") || strings.Contains(parts[0], ":")) {
+		return parts[0]
+	}
+	return "docker.io"
+}
+
+// controlPlaneImages枚举运行本控制平面节点所需的控制平面组件镜像。
+func controlPlaneImages(cfg *kubeadmapi.ClusterConfiguration) []string {
+	images := make([]string, 0, len(kubeadmconstants.ControlPlaneComponents))
+	for _, component := range kubeadmconstants.ControlPlaneComponents {
+		images = append(images, fmt.Sprintf("%s/%s:v%s", cfg.ImageRepository, component, cfg.KubernetesVersion))
+	}
+	return images
+}
+
+// RequiredImages枚举运行本控制平面节点所需的全部容器镜像：控制平面组件镜像，以及（当使用本地etcd时）
+// etcd镜像；使用外部etcd时不需要拉取etcd镜像。
+func RequiredImages(cfg *kubeadmapi.ClusterConfiguration) []string {
+	images := controlPlaneImages(cfg)
+	if cfg.Etcd.Local != nil {
+		images = append(images, fmt.Sprintf("%s/%s:%s", cfg.Etcd.Local.ImageRepository, kubeadmconstants.Etcd, cfg.Etcd.Local.ImageTag))
+	}
+	return images
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIsTransientPullError(t *testing.T) {
+	cases := []struct {
+		name      string
+		err       error
+		transient bool
+	}{
+		{
+			name:      "gRPC Unavailable是瞬时性错误",
+			err:       errors.Wrap(status.Error(codes.Unavailable, "registry暂时不可用"), "无法拉取镜像"),
+			transient: true,
+		},
+		{
+			name:      "gRPC DeadlineExceeded是瞬时性错误",
+			err:       status.Error(codes.DeadlineExceeded, "调用超时"),
+			transient: true,
+		},
+		{
+			name:      "gRPC NotFound不是瞬时性错误",
+			err:       status.Error(codes.NotFound, "镜像不存在"),
+			transient: false,
+		},
+		{
+			name:      "gRPC PermissionDenied不是瞬时性错误",
+			err:       status.Error(codes.PermissionDenied, "没有拉取该镜像的权限"),
+			transient: false,
+		},
+		{
+			name:      "带sha256摘要的永久性错误不应被误判为瞬时性",
+			err:       errors.New("failed to pull and unpack image \"registry.k8s.io/kube-apiserver@sha256:50fa599e...\": not found"),
+			transient: false,
+		},
+		{
+			name:      "纯文本超时错误是瞬时性错误",
+			err:       errors.New("dial tcp registry.k8s.io:443: i/o timeout"),
+			transient: true,
+		},
+		{
+			name:      "纯文本连接被重置是瞬时性错误",
+			err:       errors.New("read tcp: connection reset by peer"),
+			transient: true,
+		},
+		{
+			name:      "纯文本鉴权失败不是瞬时性错误",
+			err:       errors.New("unauthorized: authentication required"),
+			transient: false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isTransientPullError(tc.err); got != tc.transient {
+				t.Errorf("isTransientPullError(%v) = %v, 期望%v", tc.err, got, tc.transient)
+			}
+		})
+	}
+}
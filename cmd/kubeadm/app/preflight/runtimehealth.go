@@ -0,0 +1,52 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"context"
+	"time"
+
+	utilruntime "k8s.io/kubernetes/cmd/kubeadm/app/util/runtime"
+)
+
+// runtimeHealthCheckTimeout是ContainerRuntimeHealthCheck整体允许耗费的最长时长，覆盖
+// HealthCheck依次探测Version/ListPodSandbox/ImageFsInfo三步的总预算。
+const runtimeHealthCheckTimeout = 10 * time.Second
+
+// ContainerRuntimeHealthCheck是一项预检检查：让runtime完整走一遍HealthCheck探测的调用链，
+// 而不仅仅像IsRunning那样确认守护进程能响应一次请求。一个relist循环卡死、但套接字仍然
+// 监听着的containerd会让IsRunning通过，却会在后续kubelet-start/wait-control-plane阶段
+// 表现为一段无从下手的超时；这项检查把同一类失败提前到预检阶段，报出具体卡在哪一步RPC上。
+type ContainerRuntimeHealthCheck struct {
+	Runtime utilruntime.ContainerRuntime
+}
+
+// Name实现Checker接口
+func (ContainerRuntimeHealthCheck) Name() string {
+	return "ContainerRuntimeHealthCheck"
+}
+
+// Check实现Checker接口
+func (c ContainerRuntimeHealthCheck) Check() (warnings, errorList []error) {
+	ctx, cancel := context.WithTimeout(context.Background(), runtimeHealthCheckTimeout)
+	defer cancel()
+
+	if err := c.Runtime.HealthCheck(ctx); err != nil {
+		return nil, []error{err}
+	}
+	return nil, nil
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiclient
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// applyFieldManager是ApplyManifests做server-side apply时使用的field manager名称。
+const applyFieldManager = "kubeadm"
+
+// resourceInfo描述一个Kind对应的GroupVersionResource，以及该资源是否是命名空间级的。
+type resourceInfo struct {
+	gvr        schema.GroupVersionResource
+	namespaced bool
+}
+
+// knownResources把kubeadm内嵌插件清单中会用到的几种Kind静态地映射到其GroupVersionResource。
+// kubeadm内嵌的插件清单种类有限且已知，这里没有引入一整套基于discovery的RESTMapper。
+var knownResources = map[string]resourceInfo{
+	"Namespace":          {gvr: schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}},
+	"ServiceAccount":     {gvr: schema.GroupVersionResource{Version: "v1", Resource: "serviceaccounts"}, namespaced: true},
+	"ConfigMap":          {gvr: schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}, namespaced: true},
+	"ClusterRole":        {gvr: schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterroles"}},
+	"ClusterRoleBinding": {gvr: schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterrolebindings"}},
+	"DaemonSet":          {gvr: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "daemonsets"}, namespaced: true},
+}
+
+// ApplyManifests把documents中的每一份YAML文档依次通过server-side apply应用到集群，field manager固定为
+// "kubeadm"，并强制获取字段所有权(Force: true)，使重复运行kubeadm init phase pod-network幂等。
+func ApplyManifests(dynamicClient dynamic.Interface, documents []string) error {
+	for _, doc := range documents {
+		if err := applyDocument(dynamicClient, doc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyDocument对单份YAML文档做server-side apply。
+func applyDocument(dynamicClient dynamic.Interface, doc string) error {
+	obj := &unstructured.Unstructured{}
+	if err := yaml.Unmarshal([]byte(doc), &obj.Object); err != nil {
+		return errors.Wrap(err, "无法解析清单文档")
+	}
+	if len(obj.Object) == 0 {
+		return nil
+	}
+
+	kind := obj.GetKind()
+	info, ok := knownResources[kind]
+	if !ok {
+		return errors.Errorf("无法应用清单：Kind %q 未登记在kubeadm内置的CNI资源表中", kind)
+	}
+
+	raw, err := yaml.YAMLToJSON([]byte(doc))
+	if err != nil {
+		return errors.Wrapf(err, "无法把清单文档转换为JSON (kind=%s, name=%s)", kind, obj.GetName())
+	}
+
+	resourceClient := dynamicClient.Resource(info.gvr)
+	var namespacedClient dynamic.ResourceInterface = resourceClient
+	if info.namespaced {
+		namespace := obj.GetNamespace()
+		if namespace == "" {
+			namespace = metav1.NamespaceDefault
+		}
+		namespacedClient = resourceClient.Namespace(namespace)
+	}
+
+	force := true
+	_, err = namespacedClient.Patch(context.TODO(), obj.GetName(), types.ApplyPatchType, raw, metav1.PatchOptions{
+		FieldManager: applyFieldManager,
+		Force:        &force,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "server-side apply失败 (kind=%s, name=%s)", kind, obj.GetName())
+	}
+	return nil
+}
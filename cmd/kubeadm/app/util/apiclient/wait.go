@@ -0,0 +1,129 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiclient
+
+import (
+	"io"
+	"time"
+
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// 下面这些常量枚举了WaitEvent.Phase可以取到的全部阶段。
+const (
+	// PhaseKubeletUp表示正在等待本机kubelet把控制平面静态Pod启动起来。
+	PhaseKubeletUp = "kubelet-up"
+	// PhaseAPIServerHealthz表示正在探测kube-apiserver的/healthz端点。
+	PhaseAPIServerHealthz = "apiserver-healthz"
+	// PhaseAPIServerVersioned表示正在通过有版本的API（例如ServerVersion）探测kube-apiserver。
+	PhaseAPIServerVersioned = "apiserver-versioned"
+	// PhaseStaticPodRunning表示正在等待控制平面的静态Pod进入Running状态。
+	PhaseStaticPodRunning = "static-pod-running"
+)
+
+// WaitEvent是Waiter在等待控制平面就绪过程中对外汇报的一次进度更新。
+type WaitEvent struct {
+	Component         string        `json:"component"`
+	Phase             string        `json:"phase"`
+	Attempt           int           `json:"attempt"`
+	LastError         string        `json:"lastError,omitempty"`
+	ElapsedSinceStart time.Duration `json:"elapsedSinceStart"`
+}
+
+// Waiter是等待新建控制平面就绪所需实现的最小接口。
+type Waiter interface {
+	// WaitForAPI等待kube-apiserver可以响应请求。
+	WaitForAPI() error
+	// WaitForKubeletAndFunc在等待kubelet启动静态Pod的同时执行f（通常是WaitForAPI）。
+	WaitForKubeletAndFunc(f func() error) error
+}
+
+// WaiterWithEvents在Waiter之上额外暴露一个事件流，供调用方渲染实时进度，而不必等到整个
+// 等待过程结束才知道发生了什么。
+type WaiterWithEvents interface {
+	Waiter
+	// Events返回一个只读事件流；该事件流在WaitForKubeletAndFunc返回后关闭。
+	Events() <-chan WaitEvent
+}
+
+// kubeWaiter是WaiterWithEvents面向真实集群的实现：通过client反复探测kube-apiserver。
+type kubeWaiter struct {
+	client  clientset.Interface
+	timeout time.Duration
+	events  chan WaitEvent
+}
+
+// NewKubeWaiter返回一个针对真实集群的WaiterWithEvents实现。
+func NewKubeWaiter(client clientset.Interface, timeout time.Duration, writer io.Writer) WaiterWithEvents {
+	return &kubeWaiter{
+		client:  client,
+		timeout: timeout,
+		events:  make(chan WaitEvent, 32),
+	}
+}
+
+// Events返回本次等待过程的事件流。
+func (w *kubeWaiter) Events() <-chan WaitEvent {
+	return w.events
+}
+
+// emit尝试把一个事件推送到事件流；缓冲区已满时丢弃该事件而不是阻塞等待逻辑本身。
+func (w *kubeWaiter) emit(component, phase string, attempt int, lastErr error, start time.Time) {
+	event := WaitEvent{
+		Component:         component,
+		Phase:             phase,
+		Attempt:           attempt,
+		ElapsedSinceStart: time.Since(start),
+	}
+	if lastErr != nil {
+		event.LastError = lastErr.Error()
+	}
+	select {
+	case w.events <- event:
+	default:
+	}
+}
+
+// WaitForKubeletAndFunc在执行f前先汇报一次kubelet-up事件；当f返回后关闭事件流，
+// 让消费者(渲染实时进度的goroutine)得以退出。
+func (w *kubeWaiter) WaitForKubeletAndFunc(f func() error) error {
+	start := time.Now()
+	w.emit("kubelet", PhaseKubeletUp, 1, nil, start)
+	err := f()
+	close(w.events)
+	return err
+}
+
+// WaitForAPI反复调用ServerVersion探测kube-apiserver，每次尝试都会汇报一个apiserver-versioned事件，
+// 直至成功或超出w.timeout。
+func (w *kubeWaiter) WaitForAPI() error {
+	start := time.Now()
+	attempt := 0
+	return wait.PollImmediate(apiCallRetryInterval, w.timeout, func() (bool, error) {
+		attempt++
+		_, err := w.client.Discovery().ServerVersion()
+		w.emit("kube-apiserver", PhaseAPIServerVersioned, attempt, err, start)
+		if err != nil {
+			return false, nil
+		}
+		return true, nil
+	})
+}
+
+// apiCallRetryInterval是WaitForAPI两次探测之间的间隔。
+const apiCallRetryInterval = 2 * time.Second
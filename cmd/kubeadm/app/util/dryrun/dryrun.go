@@ -0,0 +1,285 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dryrun
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// FileToPrint描述一个试运行时要打印给用户看的文件：RealPath是实际生成内容所在的临时路径，
+// PrintPath是假装它会被写入的真实目标路径（仅用于显示）。ExistingPath非空时，如果该路径下
+// 已经存在一个真实文件，PrintDryRunDiff会打印该文件与新生成内容之间的差异，而不是整个文件内容；
+// 留空或目标文件不存在时，行为与PrintDryRunFiles一致，打印完整内容。
+type FileToPrint struct {
+	RealPath     string
+	PrintPath    string
+	ExistingPath string
+}
+
+// NewFileToPrint返回一个FileToPrint，ExistingPath留空（即总是打印完整内容）。
+func NewFileToPrint(realPath, printPath string) FileToPrint {
+	return FileToPrint{RealPath: realPath, PrintPath: printPath}
+}
+
+// NewFileToPrintWithDiff与NewFileToPrint相同，但同时记录existingPath，供PrintDryRunDiff
+// 与磁盘上已有的文件做对比。
+func NewFileToPrintWithDiff(realPath, printPath, existingPath string) FileToPrint {
+	return FileToPrint{RealPath: realPath, PrintPath: printPath, ExistingPath: existingPath}
+}
+
+// PrintDryRunFiles把files中每一个文件的完整内容打印到w。
+func PrintDryRunFiles(files []FileToPrint, w io.Writer) error {
+	for _, file := range files {
+		if err := printFile(file, w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PrintDryRunDiff与PrintDryRunFiles类似，但对于ExistingPath指向一个已存在文件的条目，
+// 只打印该文件与新生成内容之间的统一diff（3行上下文，---/+++头部使用PrintPath），而不是
+// 整个文件；对于没有ExistingPath或目标文件尚不存在的条目，行为退化为打印完整内容。
+func PrintDryRunDiff(files []FileToPrint, w io.Writer) error {
+	for _, file := range files {
+		newContent, err := os.ReadFile(file.RealPath)
+		if err != nil {
+			return errors.Wrapf(err, "无法读取文件 %q", file.RealPath)
+		}
+
+		if file.ExistingPath == "" {
+			if err := printFile(file, w); err != nil {
+				return err
+			}
+			continue
+		}
+
+		oldContent, err := os.ReadFile(file.ExistingPath)
+		if os.IsNotExist(err) {
+			if err := printFile(file, w); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return errors.Wrapf(err, "无法读取已存在的文件 %q", file.ExistingPath)
+		}
+
+		diff := unifiedDiff(string(oldContent), string(newContent), file.PrintPath, 3)
+		if diff == "" {
+			fmt.Fprintf(w, "%s: 无变化\n", file.PrintPath)
+			continue
+		}
+		fmt.Fprint(w, diff)
+	}
+	return nil
+}
+
+// printFile把单个文件的完整内容打印到w，每行前面缩进一个制表符。
+func printFile(file FileToPrint, w io.Writer) error {
+	contentBytes, err := os.ReadFile(file.RealPath)
+	if err != nil {
+		return errors.Wrapf(err, "无法读取文件 %q", file.RealPath)
+	}
+	fmt.Fprintf(w, "%s:\n", file.PrintPath)
+	fmt.Fprintln(w, "\t"+strings.Replace(string(contentBytes), "\n", "\n\t", -1))
+	return nil
+}
+
+// unifiedDiff计算oldContent与newContent之间的标准统一diff（header使用---/+++加path，
+// 每个hunk保留context行的上下文），两者完全相同时返回空字符串。
+func unifiedDiff(oldContent, newContent, path string, context int) string {
+	oldLines := splitLines(oldContent)
+	newLines := splitLines(newContent)
+
+	ops := diffOps(oldLines, newLines)
+	if !opsHaveChange(ops) {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n", path)
+	fmt.Fprintf(&b, "+++ %s\n", path)
+
+	for _, hunk := range buildHunks(ops, context) {
+		fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n", hunk.oldStart, hunk.oldCount, hunk.newStart, hunk.newCount)
+		for _, line := range hunk.lines {
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.Split(s, "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffOp是diffOps产生的逐行操作，kind为' '（不变）、'-'（删除）或'+'（新增）。
+type diffOp struct {
+	kind byte
+	text string
+}
+
+func opsHaveChange(ops []diffOp) bool {
+	for _, op := range ops {
+		if op.kind != ' ' {
+			return true
+		}
+	}
+	return false
+}
+
+// diffOps用最长公共子序列的动态规划，计算把oldLines变换为newLines所需的逐行操作序列。
+func diffOps(oldLines, newLines []string) []diffOp {
+	n, m := len(oldLines), len(newLines)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, n+m)
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case oldLines[i] == newLines[j]:
+			ops = append(ops, diffOp{kind: ' ', text: oldLines[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{kind: '-', text: oldLines[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{kind: '+', text: newLines[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, diffOp{kind: '-', text: oldLines[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, diffOp{kind: '+', text: newLines[j]})
+	}
+	return ops
+}
+
+// hunk是unifiedDiff中的一段上下文窗口及其内容。
+type hunk struct {
+	oldStart, oldCount int
+	newStart, newCount int
+	lines              []string
+}
+
+// buildHunks把ops按变更聚簇，每个簇两侧各保留最多context行的上下文，生成最终的hunk列表。
+func buildHunks(ops []diffOp, context int) []hunk {
+	changedIdx := []int{}
+	for idx, op := range ops {
+		if op.kind != ' ' {
+			changedIdx = append(changedIdx, idx)
+		}
+	}
+	if len(changedIdx) == 0 {
+		return nil
+	}
+
+	var hunks []hunk
+	start := changedIdx[0] - context
+	if start < 0 {
+		start = 0
+	}
+	end := changedIdx[0] + context + 1
+
+	flush := func(s, e int) {
+		if e > len(ops) {
+			e = len(ops)
+		}
+		oldStart, newStart := 0, 0
+		for _, op := range ops[:s] {
+			if op.kind != '+' {
+				oldStart++
+			}
+			if op.kind != '-' {
+				newStart++
+			}
+		}
+		oldCount, newCount := 0, 0
+		lines := make([]string, 0, e-s)
+		for _, op := range ops[s:e] {
+			switch op.kind {
+			case ' ':
+				oldCount++
+				newCount++
+				lines = append(lines, " "+op.text)
+			case '-':
+				oldCount++
+				lines = append(lines, "-"+op.text)
+			case '+':
+				newCount++
+				lines = append(lines, "+"+op.text)
+			}
+		}
+		hunks = append(hunks, hunk{
+			oldStart: oldStart + 1,
+			oldCount: oldCount,
+			newStart: newStart + 1,
+			newCount: newCount,
+			lines:    lines,
+		})
+	}
+
+	for _, idx := range changedIdx[1:] {
+		if idx-context <= end {
+			newEnd := idx + context + 1
+			if newEnd > end {
+				end = newEnd
+			}
+			continue
+		}
+		flush(start, end)
+		start = idx - context
+		if start < 0 {
+			start = 0
+		}
+		end = idx + context + 1
+	}
+	flush(start, end)
+
+	return hunks
+}
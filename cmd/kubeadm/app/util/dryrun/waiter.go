@@ -0,0 +1,69 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dryrun
+
+import (
+	"time"
+
+	"k8s.io/kubernetes/cmd/kubeadm/app/util/apiclient"
+)
+
+// syntheticPhases是试运行下Events()依次汇报的阶段序列，模拟一次真实等待过程会经历的进度。
+var syntheticPhases = []string{
+	apiclient.PhaseKubeletUp,
+	apiclient.PhaseAPIServerHealthz,
+	apiclient.PhaseAPIServerVersioned,
+	apiclient.PhaseStaticPodRunning,
+}
+
+// Waiter是apiclient.WaiterWithEvents在试运行下的实现：不会真的连接任何东西，所有等待条件都立即
+// 视为已满足，但仍然合成一串确定性的事件流，好让依赖--wait-events-file的仪表盘/CI日志在试运行下
+// 也能拿到可预期的输出。
+type Waiter struct{}
+
+// NewWaiter返回一个用于试运行的Waiter。
+func NewWaiter() *Waiter {
+	return &Waiter{}
+}
+
+// WaitForAPI在试运行下总是立即返回成功，因为并没有真实的API Server可供探测。
+func (w *Waiter) WaitForAPI() error {
+	return nil
+}
+
+// WaitForKubeletAndFunc在试运行下直接执行f，不做任何等待。
+func (w *Waiter) WaitForKubeletAndFunc(f func() error) error {
+	return f()
+}
+
+// Events返回一段固定的、确定性的合成事件流：每个阶段各汇报一次成功，逐步递增的ElapsedSinceStart
+// 只是为了让时间线看起来合理，并不对应真实耗时。
+func (w *Waiter) Events() <-chan apiclient.WaitEvent {
+	ch := make(chan apiclient.WaitEvent, len(syntheticPhases))
+	go func() {
+		defer close(ch)
+		for i, phase := range syntheticPhases {
+			ch <- apiclient.WaitEvent{
+				Component:         "dry-run",
+				Phase:             phase,
+				Attempt:           1,
+				ElapsedSinceStart: time.Duration(i) * 200 * time.Millisecond,
+			}
+		}
+	}()
+	return ch
+}
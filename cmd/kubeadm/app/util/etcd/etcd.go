@@ -58,19 +58,63 @@ type ClusterInterrogator interface {
 	Sync() error
 	ListMembers() ([]Member, error)
 	AddMember(name string, peerAddrs string) ([]Member, error)
+	AddMemberAsLearner(name string, peerAddrs string) ([]Member, error)
+	MemberPromote(id uint64) ([]Member, error)
 	GetMemberID(peerURL string) (uint64, error)
 	RemoveMember(id uint64) ([]Member, error)
 }
 
+// etcdLearnerPromoteBackoff 是轮询learner是否追上leader、可以被提升为投票成员时使用的退避策略。
+// 步数略少于 etcdBackoff，因为调用方（stacked etcd join阶段）通常会在自己的循环中重复调用 MemberPromote。
+var etcdLearnerPromoteBackoff = wait.Backoff{
+	Steps:    10,
+	Duration: 500 * time.Millisecond,
+	Factor:   1.5,
+	Jitter:   0.1,
+}
+
+// etcdClient is the subset of the clientv3.Client API used by Client. Extracting it as an interface means
+// every Client method that talks to etcd goes through Client.newEtcdClient instead of calling clientv3.New
+// directly, which lets tests substitute a fakeEtcdClient and exercise this package without a live cluster.
+type etcdClient interface {
+	Close() error
+	Endpoints() []string
+	Sync(ctx context.Context) error
+	MemberList(ctx context.Context) (*clientv3.MemberListResponse, error)
+	MemberAdd(ctx context.Context, peerAddrs []string) (*clientv3.MemberAddResponse, error)
+	MemberAddAsLearner(ctx context.Context, peerAddrs []string) (*clientv3.MemberAddResponse, error)
+	MemberPromote(ctx context.Context, id uint64) (*clientv3.MemberPromoteResponse, error)
+	MemberRemove(ctx context.Context, id uint64) (*clientv3.MemberRemoveResponse, error)
+	Status(ctx context.Context, endpoint string) (*clientv3.StatusResponse, error)
+}
+
+// newEtcdClientV3 is the default etcdClient factory, a thin wrapper over clientv3.New.
+func newEtcdClientV3(cfg clientv3.Config) (etcdClient, error) {
+	return clientv3.New(cfg)
+}
+
 // Client provides connection parameters for an etcd cluster
 type Client struct {
 	Endpoints []string
 	TLS       *tls.Config
+
+	// newEtcdClient builds the underlying etcd client connection. It defaults to newEtcdClientV3 and is
+	// overridable in tests (see fakeEtcdClient in etcd_test.go).
+	newEtcdClient func(cfg clientv3.Config) (etcdClient, error)
+}
+
+// clientFactory returns the etcdClient factory to use, falling back to the default when the Client was
+// constructed without going through New (e.g. a zero-value Client in a test).
+func (c *Client) clientFactory() func(clientv3.Config) (etcdClient, error) {
+	if c.newEtcdClient != nil {
+		return c.newEtcdClient
+	}
+	return newEtcdClientV3
 }
 
 // New creates a new EtcdCluster client
 func New(endpoints []string, ca, cert, key string) (*Client, error) {
-	client := Client{Endpoints: endpoints}
+	client := Client{Endpoints: endpoints, newEtcdClient: newEtcdClientV3}
 
 	if ca != "" || cert != "" || key != "" {
 		tlsInfo := transport.TLSInfo{
@@ -103,7 +147,7 @@ func NewFromCluster(client clientset.Interface, certificatesDir string) (*Client
 	klog.V(1).Infof("从 Pod 读取的 etcd Endpoint: %s", strings.Join(endpoints, ","))
 
 	// 创建一个 etcd 客户端
-	etcdClient, err := New(
+	client, err := New(
 		endpoints,
 		filepath.Join(certificatesDir, constants.EtcdCACertName),
 		filepath.Join(certificatesDir, constants.EtcdHealthcheckClientCertName),
@@ -114,13 +158,13 @@ func NewFromCluster(client clientset.Interface, certificatesDir string) (*Client
 	}
 
 	// 将客户端的 Endpoint 与 etcd 成员中的已知 Endpoint 同步。
-	err = etcdClient.Sync()
+	err = client.Sync()
 	if err != nil {
 		return nil, errors.Wrap(err, "error syncing endpoints with etcd")
 	}
-	klog.V(1).Infof("update etcd endpoints: %s", strings.Join(etcdClient.Endpoints, ","))
+	klog.V(1).Infof("update etcd endpoints: %s", strings.Join(client.Endpoints, ","))
 
-	return etcdClient, nil
+	return client, nil
 }
 
 // getEtcdEndpoints 返回 etcd 全部的 Endpoint
@@ -192,11 +236,11 @@ func getRawEtcdEndpointsFromPodAnnotationWithoutRetry(client clientset.Interface
 // Sync 将客户端的 Endpoint 与 etcd 成员中的已知 Endpoint 同步。
 func (c *Client) Sync() error {
 	// 同步 Endpoint 列表
-	var cli *clientv3.Client
+	var cli etcdClient
 	var lastError error
 	err := wait.ExponentialBackoff(etcdBackoff, func() (bool, error) {
 		var err error
-		cli, err = clientv3.New(clientv3.Config{
+		cli, err = c.clientFactory()(clientv3.Config{
 			Endpoints:   c.Endpoints,
 			DialTimeout: etcdTimeout,
 			DialOptions: []grpc.DialOption{
@@ -210,7 +254,7 @@ func (c *Client) Sync() error {
 		}
 
 		// 处理客户端错误, 原来的代码没有处理
-		defer func(cli *clientv3.Client) {
+		defer func(cli etcdClient) {
 			_ = cli.Close()
 		}(cli)
 
@@ -252,7 +296,7 @@ func (c *Client) listMembers() (*clientv3.MemberListResponse, error) {
 	var lastError error
 	var resp *clientv3.MemberListResponse
 	err := wait.ExponentialBackoff(etcdBackoff, func() (bool, error) {
-		cli, err := clientv3.New(clientv3.Config{
+		cli, err := c.clientFactory()(clientv3.Config{
 			Endpoints:   c.Endpoints,
 			DialTimeout: etcdTimeout,
 			DialOptions: []grpc.DialOption{
@@ -282,6 +326,11 @@ func (c *Client) listMembers() (*clientv3.MemberListResponse, error) {
 	return resp, nil
 }
 
+// ErrNoMemberIDForPeerURL is returned by GetMemberID when no member in the cluster matches the given
+// peer URL. Callers can errors.Is-check against it to tell "not found" apart from an RPC failure, e.g.
+// to skip member removal when the member was already removed by a previous, interrupted run.
+var ErrNoMemberIDForPeerURL = errors.New("no member found for the given peer URL")
+
 // GetMemberID returns the member ID of the given peer URL
 func (c *Client) GetMemberID(peerURL string) (uint64, error) {
 	resp, err := c.listMembers()
@@ -294,7 +343,7 @@ func (c *Client) GetMemberID(peerURL string) (uint64, error) {
 			return member.GetID(), nil
 		}
 	}
-	return 0, nil
+	return 0, ErrNoMemberIDForPeerURL
 }
 
 // ListMembers returns the member list.
@@ -317,7 +366,7 @@ func (c *Client) RemoveMember(id uint64) ([]Member, error) {
 	var lastError error
 	var resp *clientv3.MemberRemoveResponse
 	err := wait.ExponentialBackoff(etcdBackoff, func() (bool, error) {
-		cli, err := clientv3.New(clientv3.Config{
+		cli, err := c.clientFactory()(clientv3.Config{
 			Endpoints:   c.Endpoints,
 			DialTimeout: etcdTimeout,
 			DialOptions: []grpc.DialOption{
@@ -354,7 +403,10 @@ func (c *Client) RemoveMember(id uint64) ([]Member, error) {
 	return ret, nil
 }
 
-// AddMember notifies an existing etcd cluster that a new member is joining
+// AddMember notifies an existing etcd cluster that a new member is joining. AddMember is idempotent:
+// if a member already exists for the given peer URL (e.g. kubeadm join crashed after the MemberAdd RPC
+// succeeded but before the static-pod manifest was written), it returns the existing cluster view instead
+// of issuing a second MemberAdd, which etcd would otherwise reject as a duplicate-member error.
 func (c *Client) AddMember(name string, peerAddrs string) ([]Member, error) {
 	// Parse the peer address, required to add the client URL later to the list
 	// of endpoints for this client. Parsing as a first operation to make sure that
@@ -364,11 +416,37 @@ func (c *Client) AddMember(name string, peerAddrs string) ([]Member, error) {
 		return nil, errors.Wrapf(err, "error parsing peer address %s", peerAddrs)
 	}
 
+	// If a member for this peer URL already exists, this is a retry of a previously successful
+	// (but not fully completed) join: return the current cluster view without re-adding the member.
+	listResp, err := c.listMembers()
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range listResp.Members {
+		if len(m.PeerURLs) > 0 && m.PeerURLs[0] == peerAddrs {
+			klog.V(1).Infof("etcd member for peer URL %s already exists, skipping MemberAdd", peerAddrs)
+			c.Endpoints = append(c.Endpoints, GetClientURLByIP(parsedPeerAddrs.Hostname()))
+
+			ret := []Member{}
+			for _, existing := range listResp.Members {
+				memberName := existing.Name
+				if len(memberName) == 0 {
+					memberName = strconv.FormatUint(existing.ID, 16)
+				}
+				if existing.PeerURLs[0] == peerAddrs {
+					memberName = name
+				}
+				ret = append(ret, Member{Name: memberName, PeerURL: existing.PeerURLs[0]})
+			}
+			return ret, nil
+		}
+	}
+
 	// Adds a new member to the cluster
 	var lastError error
 	var resp *clientv3.MemberAddResponse
 	err = wait.ExponentialBackoff(etcdBackoff, func() (bool, error) {
-		cli, err := clientv3.New(clientv3.Config{
+		cli, err := c.clientFactory()(clientv3.Config{
 			Endpoints:   c.Endpoints,
 			DialTimeout: etcdTimeout,
 			DialOptions: []grpc.DialOption{
@@ -421,6 +499,191 @@ func (c *Client) AddMember(name string, peerAddrs string) ([]Member, error) {
 	return ret, nil
 }
 
+// AddMemberAsLearner notifies an existing etcd cluster that a new member is joining as a non-voting learner.
+// Adding the member as a learner first, and promoting it to a voting member only once it has caught up with
+// the leader (see WaitForLearnerPromotable/MemberPromote below), avoids breaking quorum on small clusters when
+// the joining control-plane node is still catching up.
+func (c *Client) AddMemberAsLearner(name string, peerAddrs string) ([]Member, error) {
+	// Parse the peer address, required to add the client URL later to the list
+	// of endpoints for this client. Parsing as a first operation to make sure that
+	// if this fails no member addition is performed on the etcd cluster.
+	parsedPeerAddrs, err := url.Parse(peerAddrs)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error parsing peer address %s", peerAddrs)
+	}
+
+	// Adds a new learner member to the cluster
+	var lastError error
+	var resp *clientv3.MemberAddResponse
+	err = wait.ExponentialBackoff(etcdBackoff, func() (bool, error) {
+		cli, err := c.clientFactory()(clientv3.Config{
+			Endpoints:   c.Endpoints,
+			DialTimeout: etcdTimeout,
+			DialOptions: []grpc.DialOption{
+				grpc.WithBlock(), // block until the underlying connection is up
+			},
+			TLS: c.TLS,
+		})
+		if err != nil {
+			lastError = err
+			return false, nil
+		}
+		defer cli.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), etcdTimeout)
+		resp, err = cli.MemberAddAsLearner(ctx, []string{peerAddrs})
+		cancel()
+		if err == nil {
+			return true, nil
+		}
+		klog.V(5).Infof("Failed to add etcd learner member: %v", err)
+		lastError = err
+		return false, nil
+	})
+	if err != nil {
+		return nil, lastError
+	}
+
+	// Returns the updated list of etcd members
+	ret := []Member{}
+	for _, m := range resp.Members {
+		// If the peer address matches, this is the learner we are adding.
+		// Use the name we passed to the function.
+		if peerAddrs == m.PeerURLs[0] {
+			ret = append(ret, Member{Name: name, PeerURL: peerAddrs})
+			continue
+		}
+		// Otherwise, we are processing other existing etcd members returned by MemberAddAsLearner.
+		memberName := m.Name
+		// In some cases during concurrent join, some members can end up without a name.
+		// Use the member ID as name for those.
+		if len(memberName) == 0 {
+			memberName = strconv.FormatUint(m.ID, 16)
+		}
+		ret = append(ret, Member{Name: memberName, PeerURL: m.PeerURLs[0]})
+	}
+
+	// Add the new member client address to the list of endpoints
+	c.Endpoints = append(c.Endpoints, GetClientURLByIP(parsedPeerAddrs.Hostname()))
+
+	return ret, nil
+}
+
+// MemberPromote promotes an etcd learner member to a full voting member. etcd only allows the promotion
+// to succeed once the learner's raft log has caught up with the leader's (i.e. it is "promotable"); until
+// then the RPC returns an error that callers should treat as retryable rather than fatal, which is why this
+// is wrapped in etcdLearnerPromoteBackoff instead of failing the join on the first attempt.
+func (c *Client) MemberPromote(id uint64) ([]Member, error) {
+	var lastError error
+	var resp *clientv3.MemberPromoteResponse
+	err := wait.ExponentialBackoff(etcdLearnerPromoteBackoff, func() (bool, error) {
+		cli, err := c.clientFactory()(clientv3.Config{
+			Endpoints:   c.Endpoints,
+			DialTimeout: etcdTimeout,
+			DialOptions: []grpc.DialOption{
+				grpc.WithBlock(), // block until the underlying connection is up
+			},
+			TLS: c.TLS,
+		})
+		if err != nil {
+			lastError = err
+			return false, nil
+		}
+		defer cli.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), etcdTimeout)
+		resp, err = cli.MemberPromote(ctx, id)
+		cancel()
+		if err == nil {
+			return true, nil
+		}
+		// The learner is not caught up yet (or some other transient error): keep retrying within the
+		// backoff budget instead of failing the join. Promotion failures that persist past the backoff
+		// budget are surfaced to the caller so the operator can retry promotion idempotently.
+		klog.V(5).Infof("etcd learner member %x not yet promotable: %v", id, err)
+		lastError = err
+		return false, nil
+	})
+	if err != nil {
+		return nil, lastError
+	}
+
+	ret := []Member{}
+	for _, m := range resp.Members {
+		ret = append(ret, Member{Name: m.Name, PeerURL: m.PeerURLs[0]})
+	}
+	return ret, nil
+}
+
+// ErrMemberNotInCluster is returned by DiscoverInitialCluster when none of the reachable endpoints know
+// about a member whose peer URL host matches targetPeerHost. Callers should treat this as "this is a brand
+// new cluster" and fall back to a `new` ETCD_INITIAL_CLUSTER_STATE bootstrap instead of retrying discovery.
+var ErrMemberNotInCluster = errors.New("local peer host not found in the etcd member list")
+
+// DiscoverInitialCluster recovers the ETCD_INITIAL_CLUSTER string (e.g. "name1=https://1.2.3.4:2380,...")
+// that a restarted stacked-etcd static pod needs to rejoin the cluster it is already a member of. The
+// manifest's baked-in --initial-cluster flag goes stale after any member add/remove, so on every manifest
+// write the static-pod phase calls this instead of reusing the value it last wrote.
+//
+// Endpoints are tried sequentially, each bounded by its own short timeout, instead of wrapping the whole
+// walk in etcdBackoff: a single unreachable endpoint should not block discovery for up to ~200 seconds when
+// another endpoint can answer immediately.
+func (c *Client) DiscoverInitialCluster(targetPeerHost, targetName string, endpoints []string, timeout time.Duration) (string, error) {
+	var lastError error
+	for _, ep := range endpoints {
+		cli, err := c.clientFactory()(clientv3.Config{
+			Endpoints:   []string{ep},
+			DialTimeout: timeout,
+			TLS:         c.TLS,
+		})
+		if err != nil {
+			lastError = err
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		resp, err := cli.MemberList(ctx)
+		cancel()
+		_ = cli.Close()
+		if err != nil {
+			klog.V(5).Infof("无法从 Endpoint %s 获取 etcd 成员列表: %v", ep, err)
+			lastError = err
+			continue
+		}
+
+		initialCluster := []string{}
+		targetFound := false
+		for _, m := range resp.Members {
+			if len(m.PeerURLs) == 0 {
+				continue
+			}
+			peerURL := m.PeerURLs[0]
+			name := m.Name
+
+			isTarget := false
+			if parsed, err := url.Parse(peerURL); err == nil && parsed.Hostname() == targetPeerHost {
+				isTarget = true
+			}
+			if isTarget {
+				targetFound = true
+				// A member with an empty name has not finished its own first start yet; this is the
+				// restarted-but-unstarted case, so substitute the name we are about to start it with.
+				if len(name) == 0 {
+					name = targetName
+				}
+			}
+			initialCluster = append(initialCluster, fmt.Sprintf("%s=%s", name, peerURL))
+		}
+
+		if !targetFound {
+			return "", ErrMemberNotInCluster
+		}
+		return strings.Join(initialCluster, ","), nil
+	}
+
+	return "", errors.Wrap(lastError, "could not discover the initial etcd cluster from any endpoint")
+}
+
 // CheckClusterHealth returns nil for status Up or error for status Down
 func (c *Client) CheckClusterHealth() error {
 	_, err := c.getClusterStatus()
@@ -435,7 +698,7 @@ func (c *Client) getClusterStatus() (map[string]*clientv3.StatusResponse, error)
 		var lastError error
 		var resp *clientv3.StatusResponse
 		err := wait.ExponentialBackoff(etcdBackoff, func() (bool, error) {
-			cli, err := clientv3.New(clientv3.Config{
+			cli, err := c.clientFactory()(clientv3.Config{
 				Endpoints:   c.Endpoints,
 				DialTimeout: etcdTimeout,
 				DialOptions: []grpc.DialOption{
@@ -0,0 +1,321 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// fakeEtcdClient is a fake implementation of the etcdClient interface, used to exercise Client's methods
+// without a live etcd cluster. failAttempts lets a test inject a number of transient failures before the
+// fake starts succeeding, so the retry behavior of etcdBackoff/etcdLearnerPromoteBackoff can be covered.
+type fakeEtcdClient struct {
+	members      []*etcdserverpb.Member
+	failAttempts int
+	calls        int
+}
+
+var errFakeTransient = errors.New("fake transient etcd error")
+
+func (f *fakeEtcdClient) Close() error { return nil }
+
+func (f *fakeEtcdClient) Endpoints() []string { return []string{"https://1.2.3.4:2379"} }
+
+func (f *fakeEtcdClient) Sync(_ context.Context) error {
+	return f.maybeFail()
+}
+
+func (f *fakeEtcdClient) MemberList(_ context.Context) (*clientv3.MemberListResponse, error) {
+	if err := f.maybeFail(); err != nil {
+		return nil, err
+	}
+	return &clientv3.MemberListResponse{Members: f.members}, nil
+}
+
+func (f *fakeEtcdClient) MemberAdd(_ context.Context, peerAddrs []string) (*clientv3.MemberAddResponse, error) {
+	if err := f.maybeFail(); err != nil {
+		return nil, err
+	}
+	f.members = append(f.members, &etcdserverpb.Member{ID: uint64(len(f.members) + 1), PeerURLs: peerAddrs})
+	return &clientv3.MemberAddResponse{Members: f.members}, nil
+}
+
+func (f *fakeEtcdClient) MemberAddAsLearner(_ context.Context, peerAddrs []string) (*clientv3.MemberAddResponse, error) {
+	if err := f.maybeFail(); err != nil {
+		return nil, err
+	}
+	f.members = append(f.members, &etcdserverpb.Member{ID: uint64(len(f.members) + 1), PeerURLs: peerAddrs, IsLearner: true})
+	return &clientv3.MemberAddResponse{Members: f.members}, nil
+}
+
+func (f *fakeEtcdClient) MemberPromote(_ context.Context, id uint64) (*clientv3.MemberPromoteResponse, error) {
+	if err := f.maybeFail(); err != nil {
+		return nil, err
+	}
+	for _, m := range f.members {
+		if m.ID == id {
+			m.IsLearner = false
+		}
+	}
+	return &clientv3.MemberPromoteResponse{Members: f.members}, nil
+}
+
+func (f *fakeEtcdClient) MemberRemove(_ context.Context, id uint64) (*clientv3.MemberRemoveResponse, error) {
+	if err := f.maybeFail(); err != nil {
+		return nil, err
+	}
+	remaining := []*etcdserverpb.Member{}
+	for _, m := range f.members {
+		if m.ID != id {
+			remaining = append(remaining, m)
+		}
+	}
+	f.members = remaining
+	return &clientv3.MemberRemoveResponse{Members: f.members}, nil
+}
+
+func (f *fakeEtcdClient) Status(_ context.Context, endpoint string) (*clientv3.StatusResponse, error) {
+	if err := f.maybeFail(); err != nil {
+		return nil, err
+	}
+	return &clientv3.StatusResponse{}, nil
+}
+
+// maybeFail returns errFakeTransient for the first failAttempts calls, then nil. This lets tests simulate
+// an etcd cluster that is briefly unreachable before a retry within etcdBackoff/etcdLearnerPromoteBackoff
+// succeeds, and one that never recovers within the backoff budget.
+func (f *fakeEtcdClient) maybeFail() error {
+	f.calls++
+	if f.calls <= f.failAttempts {
+		return errFakeTransient
+	}
+	return nil
+}
+
+func newFakeClient(members []*etcdserverpb.Member, failAttempts int) *Client {
+	fake := &fakeEtcdClient{members: members, failAttempts: failAttempts}
+	return &Client{
+		Endpoints:     []string{"https://1.2.3.4:2379"},
+		newEtcdClient: func(clientv3.Config) (etcdClient, error) { return fake, nil },
+	}
+}
+
+func TestAddMemberIdempotent(t *testing.T) {
+	tests := []struct {
+		name         string
+		existing     []*etcdserverpb.Member
+		peerAddrs    string
+		wantNewAdd   bool
+		wantEndpoint string
+	}{
+		{
+			name:         "member does not exist yet: a new MemberAdd is issued",
+			existing:     nil,
+			peerAddrs:    "https://1.2.3.5:2380",
+			wantNewAdd:   true,
+			wantEndpoint: "https://1.2.3.5:2379",
+		},
+		{
+			name: "member already exists for this peer URL: no new MemberAdd is issued",
+			existing: []*etcdserverpb.Member{
+				{ID: 1, Name: "old-name", PeerURLs: []string{"https://1.2.3.5:2380"}},
+			},
+			peerAddrs:    "https://1.2.3.5:2380",
+			wantNewAdd:   false,
+			wantEndpoint: "https://1.2.3.5:2379",
+		},
+	}
+
+	for _, rt := range tests {
+		t.Run(rt.name, func(t *testing.T) {
+			membersBefore := len(rt.existing)
+			c := newFakeClient(rt.existing, 0)
+
+			members, err := c.AddMember("new-name", rt.peerAddrs)
+			if err != nil {
+				t.Fatalf("AddMember returned an error: %v", err)
+			}
+
+			found := false
+			for _, m := range members {
+				if m.PeerURL == rt.peerAddrs && m.Name == "new-name" {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("expected a member named %q with peer URL %q, got %v", "new-name", rt.peerAddrs, members)
+			}
+
+			wantMemberCount := membersBefore
+			if rt.wantNewAdd {
+				wantMemberCount++
+			}
+			if len(members) != wantMemberCount {
+				t.Errorf("expected %d members after AddMember, got %d", wantMemberCount, len(members))
+			}
+
+			gotEndpoint := c.Endpoints[len(c.Endpoints)-1]
+			if gotEndpoint != rt.wantEndpoint {
+				t.Errorf("expected last endpoint to be %q, got %q", rt.wantEndpoint, gotEndpoint)
+			}
+		})
+	}
+}
+
+func TestAddMemberAsLearnerAndPromote(t *testing.T) {
+	c := newFakeClient(nil, 0)
+
+	members, err := c.AddMemberAsLearner("learner-1", "https://1.2.3.5:2380")
+	if err != nil {
+		t.Fatalf("AddMemberAsLearner returned an error: %v", err)
+	}
+	if len(members) != 1 || members[0].Name != "learner-1" {
+		t.Fatalf("expected a single learner member named learner-1, got %v", members)
+	}
+
+	if _, err := c.MemberPromote(1); err != nil {
+		t.Fatalf("MemberPromote returned an error: %v", err)
+	}
+}
+
+func TestMemberPromoteRetriesOnNotYetPromotable(t *testing.T) {
+	// The learner fails to promote on the first two attempts (not yet caught up with the leader),
+	// then succeeds; MemberPromote must retry within etcdLearnerPromoteBackoff instead of failing fast.
+	existing := []*etcdserverpb.Member{{ID: 1, Name: "learner-1", PeerURLs: []string{"https://1.2.3.5:2380"}, IsLearner: true}}
+	c := newFakeClient(existing, 2)
+
+	members, err := c.MemberPromote(1)
+	if err != nil {
+		t.Fatalf("expected MemberPromote to succeed after retrying, got error: %v", err)
+	}
+	if len(members) != 1 {
+		t.Errorf("expected 1 member, got %d", len(members))
+	}
+}
+
+func TestMemberPromoteSurfacesErrorAfterBackoffExhausted(t *testing.T) {
+	// More failures than etcdLearnerPromoteBackoff.Steps allows for: MemberPromote must surface the
+	// last error instead of retrying forever, so the operator can retry promotion idempotently.
+	existing := []*etcdserverpb.Member{{ID: 1, Name: "learner-1", PeerURLs: []string{"https://1.2.3.5:2380"}, IsLearner: true}}
+	c := newFakeClient(existing, etcdLearnerPromoteBackoff.Steps+5)
+
+	if _, err := c.MemberPromote(1); err == nil {
+		t.Fatal("expected MemberPromote to return an error once the backoff budget is exhausted")
+	}
+}
+
+func TestGetMemberIDNotFound(t *testing.T) {
+	existing := []*etcdserverpb.Member{{ID: 1, Name: "m1", PeerURLs: []string{"https://1.2.3.5:2380"}}}
+	c := newFakeClient(existing, 0)
+
+	if _, err := c.GetMemberID("https://9.9.9.9:2380"); err != ErrNoMemberIDForPeerURL {
+		t.Errorf("expected ErrNoMemberIDForPeerURL, got %v", err)
+	}
+}
+
+func TestDiscoverInitialCluster(t *testing.T) {
+	tests := []struct {
+		name            string
+		existing        []*etcdserverpb.Member
+		targetPeerHost  string
+		targetName      string
+		wantErr         error
+		wantClusterSpec string
+	}{
+		{
+			name: "target member already started: its existing name is kept",
+			existing: []*etcdserverpb.Member{
+				{ID: 1, Name: "node-1", PeerURLs: []string{"https://1.2.3.4:2380"}},
+				{ID: 2, Name: "node-2", PeerURLs: []string{"https://1.2.3.5:2380"}},
+			},
+			targetPeerHost:  "1.2.3.4",
+			targetName:      "node-1",
+			wantClusterSpec: "node-1=https://1.2.3.4:2380,node-2=https://1.2.3.5:2380",
+		},
+		{
+			name: "target member restarted but never started: the empty name is substituted",
+			existing: []*etcdserverpb.Member{
+				{ID: 1, Name: "", PeerURLs: []string{"https://1.2.3.4:2380"}},
+				{ID: 2, Name: "node-2", PeerURLs: []string{"https://1.2.3.5:2380"}},
+			},
+			targetPeerHost:  "1.2.3.4",
+			targetName:      "node-1",
+			wantClusterSpec: "node-1=https://1.2.3.4:2380,node-2=https://1.2.3.5:2380",
+		},
+		{
+			name: "target host is not a member of the cluster",
+			existing: []*etcdserverpb.Member{
+				{ID: 2, Name: "node-2", PeerURLs: []string{"https://1.2.3.5:2380"}},
+			},
+			targetPeerHost: "9.9.9.9",
+			targetName:     "node-1",
+			wantErr:        ErrMemberNotInCluster,
+		},
+	}
+
+	for _, rt := range tests {
+		t.Run(rt.name, func(t *testing.T) {
+			c := newFakeClient(rt.existing, 0)
+
+			got, err := c.DiscoverInitialCluster(rt.targetPeerHost, rt.targetName, c.Endpoints, time.Second)
+			if rt.wantErr != nil {
+				if err != rt.wantErr {
+					t.Fatalf("expected error %v, got %v", rt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DiscoverInitialCluster returned an unexpected error: %v", err)
+			}
+			if got != rt.wantClusterSpec {
+				t.Errorf("expected cluster spec %q, got %q", rt.wantClusterSpec, got)
+			}
+		})
+	}
+}
+
+func TestDiscoverInitialClusterSkipsUnreachableEndpoint(t *testing.T) {
+	// The first endpoint is unreachable; discovery should move on to the next one instead of failing.
+	fake := &fakeEtcdClient{members: []*etcdserverpb.Member{
+		{ID: 1, Name: "node-1", PeerURLs: []string{"https://1.2.3.4:2380"}},
+	}}
+	attempt := 0
+	c := &Client{
+		Endpoints: []string{"https://10.0.0.1:2379", "https://10.0.0.2:2379"},
+		newEtcdClient: func(cfg clientv3.Config) (etcdClient, error) {
+			attempt++
+			if attempt == 1 {
+				return nil, errFakeTransient
+			}
+			return fake, nil
+		},
+	}
+
+	got, err := c.DiscoverInitialCluster("1.2.3.4", "node-1", c.Endpoints, time.Second)
+	if err != nil {
+		t.Fatalf("expected discovery to succeed via the second endpoint, got error: %v", err)
+	}
+	if got != "node-1=https://1.2.3.4:2380" {
+		t.Errorf("unexpected cluster spec: %q", got)
+	}
+}
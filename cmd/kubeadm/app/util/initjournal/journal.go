@@ -0,0 +1,189 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package initjournal把kubeadm init每个阶段的执行状态、起止时间，以及创建该执行记录时生效的配置
+// 内容指纹持久化到--state-dir下的一份JSON文件中，使kubeadm init --resume能够在中断后跳过已经
+// 成功执行过的阶段，而不需要引入任何外部编排系统来记录进度。
+package initjournal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultStateDir是未通过--state-dir覆盖时，存放Journal文件的默认目录。
+const DefaultStateDir = "/var/lib/kubeadm"
+
+// fileName是Journal文件在--state-dir目录下使用的文件名。
+const fileName = "init-state.json"
+
+// Status描述一个阶段在Journal中记录的执行状态。
+type Status string
+
+const (
+	// StatusRunning表示该阶段已经开始执行, 但还没有结束(成功或失败)。
+	// 进程在这个状态落盘后被中断, 是--resume之后需要重新执行它的信号。
+	StatusRunning Status = "running"
+	// StatusSucceeded表示该阶段已经成功执行完毕。
+	StatusSucceeded Status = "succeeded"
+	// StatusFailed表示该阶段最近一次执行以失败告终。
+	StatusFailed Status = "failed"
+)
+
+// PhaseState记录单个阶段(以workflow.Runner生成的完整路径名为键, 例如"control-plane/apiserver")的
+// 执行状态与起止时间。
+type PhaseState struct {
+	Status    Status    `json:"status"`
+	StartedAt time.Time `json:"startedAt,omitempty"`
+	EndedAt   time.Time `json:"endedAt,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Journal是持久化到--state-dir/init-state.json中的完整内容。
+type Journal struct {
+	// ConfigHash是创建该Journal时生效的InitConfiguration的内容指纹, --resume时用它校验配置在两次
+	// 执行之间是否发生了变化。
+	ConfigHash string `json:"configHash"`
+	// Phases按阶段的完整路径名索引其执行状态。
+	Phases map[string]PhaseState `json:"phases"`
+}
+
+// New返回一个与configHash绑定的全新空Journal。
+func New(configHash string) *Journal {
+	return &Journal{
+		ConfigHash: configHash,
+		Phases:     map[string]PhaseState{},
+	}
+}
+
+// path拼接stateDir下Journal文件的完整路径。
+func path(stateDir string) string {
+	return filepath.Join(stateDir, fileName)
+}
+
+// Load从stateDir读取既有的Journal；文件不存在时返回(nil, nil), 调用方据此判断这是一次全新的执行
+// 还是一次续跑。
+func Load(stateDir string) (*Journal, error) {
+	raw, err := os.ReadFile(path(stateDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "无法读取--state-dir下既有的Journal文件")
+	}
+
+	j := &Journal{}
+	if err := json.Unmarshal(raw, j); err != nil {
+		return nil, errors.Wrap(err, "无法解析--state-dir下既有的Journal文件")
+	}
+	return j, nil
+}
+
+// Save把Journal以原子方式(先写入同目录下的临时文件, 再rename为正式文件)写入stateDir, 确保进程
+// 在写入过程中被中断时, --state-dir下不会留下一份损坏、半写的Journal文件。
+func (j *Journal) Save(stateDir string) error {
+	if err := os.MkdirAll(stateDir, 0700); err != nil {
+		return errors.Wrap(err, "无法创建--state-dir目录")
+	}
+
+	raw, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "无法序列化Journal")
+	}
+
+	tmp, err := os.CreateTemp(stateDir, ".init-state-*.json.tmp")
+	if err != nil {
+		return errors.Wrap(err, "无法创建Journal临时文件")
+	}
+	tmpName := tmp.Name()
+
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return errors.Wrap(err, "无法写入Journal临时文件")
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return errors.Wrap(err, "无法关闭Journal临时文件")
+	}
+
+	if err := os.Rename(tmpName, path(stateDir)); err != nil {
+		os.Remove(tmpName)
+		return errors.Wrap(err, "无法把Journal临时文件原子替换为正式文件")
+	}
+	return nil
+}
+
+// MarkRunning把phase标记为running并记录开始时间, 随后立即Save, 使进程若恰好在该阶段执行期间
+// 被中断, --state-dir中也能准确反映"这个阶段没有跑完", 而不是仍停留在上一个阶段的succeeded状态。
+func (j *Journal) MarkRunning(stateDir, phase string) error {
+	j.Phases[phase] = PhaseState{Status: StatusRunning, StartedAt: time.Now()}
+	return j.Save(stateDir)
+}
+
+// MarkSucceeded把phase标记为succeeded并记录结束时间, 随后立即Save。
+func (j *Journal) MarkSucceeded(stateDir, phase string) error {
+	state := j.Phases[phase]
+	state.Status = StatusSucceeded
+	state.EndedAt = time.Now()
+	state.Error = ""
+	j.Phases[phase] = state
+	return j.Save(stateDir)
+}
+
+// MarkFailed把phase标记为failed, 记录结束时间与错误信息, 随后立即Save。
+func (j *Journal) MarkFailed(stateDir, phase string, phaseErr error) error {
+	state := j.Phases[phase]
+	state.Status = StatusFailed
+	state.EndedAt = time.Now()
+	state.Error = phaseErr.Error()
+	j.Phases[phase] = state
+	return j.Save(stateDir)
+}
+
+// Succeeded返回phase在Journal中是否已经被标记为succeeded, 供Runner在--resume时判断是否可以跳过
+// 该阶段。
+func (j *Journal) Succeeded(phase string) bool {
+	return j.Phases[phase].Status == StatusSucceeded
+}
+
+// Discard删除stateDir下既有的Journal文件(如果存在), 供--restart这类"放弃上一次的执行记录,
+// 把这一次当作全新开始"的场景使用, 防止之后再次--resume时误读到这一次被主动放弃的状态。
+// 文件本就不存在时视为成功。
+func Discard(stateDir string) error {
+	if err := os.Remove(path(stateDir)); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "无法删除--state-dir下既有的Journal文件")
+	}
+	return nil
+}
+
+// HashConfig返回obj的YAML序列化内容的SHA256摘要(十六进制), 用于在--resume时校验InitConfiguration
+// 自Journal创建以来是否发生了变化。
+func HashConfig(obj interface{}) (string, error) {
+	raw, err := yaml.Marshal(obj)
+	if err != nil {
+		return "", errors.Wrap(err, "无法序列化配置以计算指纹")
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), nil
+}
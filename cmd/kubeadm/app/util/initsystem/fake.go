@@ -0,0 +1,75 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package initsystem
+
+// FakeInitSystem是InitSystem的一个内存实现，只记录每个方法被调用过的服务名、不执行任何真实的
+// 系统调用，供本包及其调用方(如kubeadm/app/phases/kubelet)在测试里替身真实的init系统后端。
+// 虽然文件名不带_test后缀，它仍然只打算在测试代码里被引用，和upstream kubeadm自身的做法一致。
+type FakeInitSystem struct {
+	Exists  map[string]bool
+	Enabled map[string]bool
+	Active  map[string]bool
+
+	StartErr   error
+	StopErr    error
+	RestartErr error
+
+	Started   []string
+	Stopped   []string
+	Restarted []string
+}
+
+// NewFakeInitSystem返回一个所有映射都已经初始化好的FakeInitSystem，调用方可以直接往里面写
+// 期望的探测结果，不必先判空。
+func NewFakeInitSystem() *FakeInitSystem {
+	return &FakeInitSystem{
+		Exists:  map[string]bool{},
+		Enabled: map[string]bool{},
+		Active:  map[string]bool{},
+	}
+}
+
+func (f *FakeInitSystem) ServiceStart(service string) error {
+	f.Started = append(f.Started, service)
+	return f.StartErr
+}
+
+func (f *FakeInitSystem) ServiceStop(service string) error {
+	f.Stopped = append(f.Stopped, service)
+	return f.StopErr
+}
+
+func (f *FakeInitSystem) ServiceRestart(service string) error {
+	f.Restarted = append(f.Restarted, service)
+	return f.RestartErr
+}
+
+func (f *FakeInitSystem) ServiceExists(service string) bool {
+	return f.Exists[service]
+}
+
+func (f *FakeInitSystem) ServiceIsEnabled(service string) bool {
+	return f.Enabled[service]
+}
+
+func (f *FakeInitSystem) ServiceIsActive(service string) bool {
+	return f.Active[service]
+}
+
+func (f *FakeInitSystem) EnableCommand(service string) string {
+	return "fake-enable " + service
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package initsystem abstracts over the various init systems that a node kubeadm manages
+// kubelet on top of might be running: systemd and SysVinit-style /etc/init.d on most Linux
+// distributions, OpenRC and runit on distributions that don't ship systemd, the Windows Service
+// Control Manager, and launchd on macOS.
+package initsystem
+
+import (
+	"fmt"
+	"sort"
+)
+
+// InitSystem is the interface that kubeadm talks to in order to manage the kubelet service,
+// regardless of which init system actually owns it on the current host.
+type InitSystem interface {
+	// ServiceStart tries to start a specific service.
+	ServiceStart(service string) error
+	// ServiceStop tries to stop a specific service.
+	ServiceStop(service string) error
+	// ServiceRestart tries to reload the environment and restart the specific service.
+	ServiceRestart(service string) error
+	// ServiceExists ensures the service is defined for this init system.
+	ServiceExists(service string) bool
+	// ServiceIsEnabled ensures the service is enabled to start automatically at boot.
+	ServiceIsEnabled(service string) bool
+	// ServiceIsActive ensures the service is running, or attempting to run.
+	ServiceIsActive(service string) bool
+	// EnableCommand returns the command a user could run to enable the service; used for
+	// messaging purposes only, it is never executed by kubeadm itself.
+	EnableCommand(service string) string
+}
+
+// detectFunc尝试判断当前主机是不是由它所代表的那种init系统管理，是则返回对应的InitSystem实现。
+type detectFunc func() (system InitSystem, found bool)
+
+// registryEntry把一个后端的探测函数和它的优先级绑在一起；优先级数字越小越先被尝试。
+type registryEntry struct {
+	priority int
+	detect   detectFunc
+}
+
+// registry按后端注册的先后收集所有已知后端；具体的尝试顺序在GetInitSystem里按priority重新排序
+// 决定，与Register调用的先后顺序无关，这样各平台专属文件(windows_windows.go、launchd_darwin.go)
+// 可以各自在自己的init()里调用Register，不需要关心同包内其它文件的加载顺序。
+var registry []registryEntry
+
+// Register把一个后端加入registry。priority越小代表这个后端在探测时越被优先尝试；同一台主机上
+// 可能会有多个后端的探测函数都返回found=true(例如同时装了systemd和sysvinit兼容脚本)，此时
+// priority最小的后端胜出。
+func Register(priority int, detect detectFunc) {
+	registry = append(registry, registryEntry{priority: priority, detect: detect})
+}
+
+// GetInitSystem按priority从小到大依次尝试registry中的后端，返回第一个探测成功的。
+func GetInitSystem() (InitSystem, error) {
+	sorted := make([]registryEntry, len(registry))
+	copy(sorted, registry)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].priority < sorted[j].priority
+	})
+
+	for _, entry := range sorted {
+		if system, found := entry.detect(); found {
+			return system, nil
+		}
+	}
+	return nil, fmt.Errorf("no supported init system detected, skipping checking for services")
+}
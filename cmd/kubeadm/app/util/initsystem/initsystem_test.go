@@ -0,0 +1,91 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package initsystem
+
+import "testing"
+
+// withRegistry替换全局registry为空白状态，运行fn，并在结束时恢复真实的registry(由本包各后端
+// 文件的init()填充)，避免这个测试污染同一进程里运行的其它测试。
+func withRegistry(t *testing.T, fn func()) {
+	t.Helper()
+	saved := registry
+	registry = nil
+	defer func() { registry = saved }()
+	fn()
+}
+
+func TestGetInitSystemPicksHighestPriorityMatch(t *testing.T) {
+	withRegistry(t, func() {
+		low := NewFakeInitSystem()
+		high := NewFakeInitSystem()
+		Register(20, func() (InitSystem, bool) { return low, true })
+		Register(10, func() (InitSystem, bool) { return high, true })
+
+		got, err := GetInitSystem()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != InitSystem(high) {
+			t.Fatalf("expected the higher-priority (lower number) backend to win")
+		}
+	})
+}
+
+func TestGetInitSystemSkipsNonMatchingBackends(t *testing.T) {
+	withRegistry(t, func() {
+		want := NewFakeInitSystem()
+		Register(10, func() (InitSystem, bool) { return nil, false })
+		Register(20, func() (InitSystem, bool) { return want, true })
+
+		got, err := GetInitSystem()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != InitSystem(want) {
+			t.Fatalf("expected the only matching backend to be returned")
+		}
+	})
+}
+
+func TestGetInitSystemNoMatch(t *testing.T) {
+	withRegistry(t, func() {
+		Register(10, func() (InitSystem, bool) { return nil, false })
+
+		if _, err := GetInitSystem(); err == nil {
+			t.Fatal("expected an error when no backend matches")
+		}
+	})
+}
+
+func TestFakeInitSystemRecordsCalls(t *testing.T) {
+	f := NewFakeInitSystem()
+	f.Exists["kubelet"] = true
+	f.Active["kubelet"] = true
+
+	if !f.ServiceExists("kubelet") {
+		t.Fatal("expected ServiceExists to reflect the configured Exists map")
+	}
+	if err := f.ServiceRestart("kubelet"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(f.Restarted) != 1 || f.Restarted[0] != "kubelet" {
+		t.Fatalf("expected ServiceRestart to record the call, got %v", f.Restarted)
+	}
+	if !f.ServiceIsActive("kubelet") {
+		t.Fatal("expected ServiceIsActive to reflect the configured Active map")
+	}
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package initsystem
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// openrcPriority与sysvinitPriority相同: 二者互斥(一台主机要么装rc-service要么装service)，谁先
+// 探测到谁胜出，顺序本身不重要。
+const openrcPriority = 20
+
+// OpenRCInitSystem通过rc-service/rc-update管理服务，是Alpine Linux等不使用systemd的发行版上的
+// 默认init系统。
+type OpenRCInitSystem struct{}
+
+func init() {
+	Register(openrcPriority, func() (InitSystem, bool) {
+		if _, err := exec.LookPath("rc-service"); err != nil {
+			return nil, false
+		}
+		return &OpenRCInitSystem{}, true
+	})
+}
+
+func (o OpenRCInitSystem) ServiceStart(service string) error {
+	return exec.Command("rc-service", service, "start").Run()
+}
+
+func (o OpenRCInitSystem) ServiceStop(service string) error {
+	return exec.Command("rc-service", service, "stop").Run()
+}
+
+func (o OpenRCInitSystem) ServiceRestart(service string) error {
+	return exec.Command("rc-service", service, "restart").Run()
+}
+
+// ServiceExists判断/etc/init.d下是否存在对应的OpenRC脚本; OpenRC沿用了sysvinit的脚本目录布局。
+func (o OpenRCInitSystem) ServiceExists(service string) bool {
+	_, err := os.Stat("/etc/init.d/" + service)
+	return err == nil
+}
+
+// ServiceIsEnabled通过"rc-update show default"的输出判断服务是否被加入了default运行级别。
+func (o OpenRCInitSystem) ServiceIsEnabled(service string) bool {
+	outBytes, _ := exec.Command("rc-update", "show", "default").Output()
+	return strings.Contains(string(outBytes), service)
+}
+
+func (o OpenRCInitSystem) ServiceIsActive(service string) bool {
+	return exec.Command("rc-service", service, "status").Run() == nil
+}
+
+func (o OpenRCInitSystem) EnableCommand(service string) string {
+	return fmt.Sprintf("rc-update add %s default", service)
+}
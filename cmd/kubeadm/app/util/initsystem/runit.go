@@ -0,0 +1,78 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package initsystem
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// runitPriority与其它非systemd的Linux后端相同。
+const runitPriority = 20
+
+// runitServiceDir是runit约定的服务定义目录(通常装在/etc/sv/<service>下)。
+const runitServiceDir = "/etc/sv"
+
+// runitEnabledDir是runit约定的"已启用"目录；/etc/service下每一个指向runitServiceDir的符号链接
+// 都会被runsvdir在开机时自动监管起来。
+const runitEnabledDir = "/etc/service"
+
+// RunitInitSystem通过sv命令管理服务，用于Void Linux等默认使用runit的发行版。
+type RunitInitSystem struct{}
+
+func init() {
+	Register(runitPriority, func() (InitSystem, bool) {
+		if _, err := exec.LookPath("sv"); err != nil {
+			return nil, false
+		}
+		return &RunitInitSystem{}, true
+	})
+}
+
+func (r RunitInitSystem) ServiceStart(service string) error {
+	return exec.Command("sv", "start", service).Run()
+}
+
+func (r RunitInitSystem) ServiceStop(service string) error {
+	return exec.Command("sv", "stop", service).Run()
+}
+
+func (r RunitInitSystem) ServiceRestart(service string) error {
+	return exec.Command("sv", "restart", service).Run()
+}
+
+// ServiceExists判断runitServiceDir下是否存在这个服务的定义目录。
+func (r RunitInitSystem) ServiceExists(service string) bool {
+	_, err := os.Stat(filepath.Join(runitServiceDir, service))
+	return err == nil
+}
+
+// ServiceIsEnabled判断runitEnabledDir下是否有对应的符号链接，这是runit用来表示"开机自启"的方式。
+func (r RunitInitSystem) ServiceIsEnabled(service string) bool {
+	_, err := os.Lstat(filepath.Join(runitEnabledDir, service))
+	return err == nil
+}
+
+func (r RunitInitSystem) ServiceIsActive(service string) bool {
+	return exec.Command("sv", "status", service).Run() == nil
+}
+
+func (r RunitInitSystem) EnableCommand(service string) string {
+	return fmt.Sprintf("ln -s %s %s", filepath.Join(runitServiceDir, service), runitEnabledDir)
+}
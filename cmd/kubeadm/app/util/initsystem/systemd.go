@@ -0,0 +1,89 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package initsystem
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// systemdPriority排在其它Linux后端之前: 即使一台主机上同时存在/etc/init.d兼容脚本，只要装了
+// systemd，真正管理服务的也是它。
+const systemdPriority = 10
+
+// SystemdInitSystem通过systemctl管理由systemd运行的服务，是绝大多数现代Linux发行版的默认后端。
+type SystemdInitSystem struct{}
+
+func init() {
+	Register(systemdPriority, func() (InitSystem, bool) {
+		if _, err := exec.LookPath("systemctl"); err != nil {
+			return nil, false
+		}
+		return &SystemdInitSystem{}, true
+	})
+}
+
+func (sysd SystemdInitSystem) reloadSystemd() error {
+	if err := exec.Command("systemctl", "daemon-reload").Run(); err != nil {
+		return fmt.Errorf("failed to reload systemd: %v", err)
+	}
+	return nil
+}
+
+// ServiceStart先执行daemon-reload，再启动服务，确保单元文件的最新改动被systemd感知。
+func (sysd SystemdInitSystem) ServiceStart(service string) error {
+	if err := sysd.reloadSystemd(); err != nil {
+		return err
+	}
+	return exec.Command("systemctl", "start", service).Run()
+}
+
+// ServiceStop停止服务；停止之前不需要daemon-reload。
+func (sysd SystemdInitSystem) ServiceStop(service string) error {
+	return exec.Command("systemctl", "stop", service).Run()
+}
+
+// ServiceRestart先执行daemon-reload，再重启服务。
+func (sysd SystemdInitSystem) ServiceRestart(service string) error {
+	if err := sysd.reloadSystemd(); err != nil {
+		return err
+	}
+	return exec.Command("systemctl", "restart", service).Run()
+}
+
+// ServiceExists通过systemctl status的输出判断服务单元是否被systemd认识，而不是判断它当前是否
+// 在运行；"Loaded: not-found"是systemd对"没有这个单元"的固定措辞。
+func (sysd SystemdInitSystem) ServiceExists(service string) bool {
+	outBytes, _ := exec.Command("systemctl", "status", service).Output()
+	return !strings.Contains(string(outBytes), "Loaded: not-found")
+}
+
+// ServiceIsEnabled对应"systemctl is-enabled"，退出码为0表示服务已经设置为开机自启。
+func (sysd SystemdInitSystem) ServiceIsEnabled(service string) bool {
+	return exec.Command("systemctl", "is-enabled", service).Run() == nil
+}
+
+// ServiceIsActive对应"systemctl is-active"，退出码为0表示服务正在运行或正在尝试运行。
+func (sysd SystemdInitSystem) ServiceIsActive(service string) bool {
+	return exec.Command("systemctl", "is-active", service).Run() == nil
+}
+
+// EnableCommand仅用于拼装提示信息，kubeadm自己不会执行它。
+func (sysd SystemdInitSystem) EnableCommand(service string) string {
+	return fmt.Sprintf("systemctl enable %s.service", service)
+}
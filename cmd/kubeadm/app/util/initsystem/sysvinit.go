@@ -0,0 +1,71 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package initsystem
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// sysvinitPriority比systemd低一级：只有探测不到systemctl时才会用到这个后端。
+const sysvinitPriority = 20
+
+// SysVInitSystem通过/etc/init.d脚本和"service"命令管理服务，用于没有systemd的老式Linux发行版
+// (或者未启用systemd、但保留了sysvinit兼容层的系统)。
+type SysVInitSystem struct{}
+
+func init() {
+	Register(sysvinitPriority, func() (InitSystem, bool) {
+		if _, err := exec.LookPath("service"); err != nil {
+			return nil, false
+		}
+		return &SysVInitSystem{}, true
+	})
+}
+
+func (sysv SysVInitSystem) ServiceStart(service string) error {
+	return exec.Command("service", service, "start").Run()
+}
+
+func (sysv SysVInitSystem) ServiceStop(service string) error {
+	return exec.Command("service", service, "stop").Run()
+}
+
+func (sysv SysVInitSystem) ServiceRestart(service string) error {
+	return exec.Command("service", service, "restart").Run()
+}
+
+// ServiceExists判断/etc/init.d下是否存在对应的脚本。
+func (sysv SysVInitSystem) ServiceExists(service string) bool {
+	_, err := os.Stat("/etc/init.d/" + service)
+	return err == nil
+}
+
+// ServiceIsEnabled通过查找/etc/rc?.d下是否有对应的S开头启动链接来判断服务是否被设置为开机自启。
+func (sysv SysVInitSystem) ServiceIsEnabled(service string) bool {
+	outBytes, _ := exec.Command("sh", "-c", fmt.Sprintf("find /etc/rc?.d/ -name 'S*%s'", service)).Output()
+	return len(outBytes) != 0
+}
+
+func (sysv SysVInitSystem) ServiceIsActive(service string) bool {
+	return exec.Command("service", service, "status").Run() == nil
+}
+
+func (sysv SysVInitSystem) EnableCommand(service string) string {
+	return fmt.Sprintf("update-rc.d %s defaults", service)
+}
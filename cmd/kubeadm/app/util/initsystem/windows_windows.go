@@ -0,0 +1,114 @@
+//go:build windows
+
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package initsystem
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// windowsPriority: Windows下只有这一个后端，数值仅为了和其它平台保持同一量级。
+const windowsPriority = 10
+
+// WindowsInitSystem通过Windows服务控制管理器(SCM)管理服务，例如以"kubelet"为名注册的Windows服务。
+type WindowsInitSystem struct{}
+
+func init() {
+	Register(windowsPriority, func() (InitSystem, bool) {
+		return &WindowsInitSystem{}, true
+	})
+}
+
+// withService连接SCM、打开指定服务，并把后续操作交给fn，确保句柄总是被正确关闭。
+func (w WindowsInitSystem) withService(service string, fn func(*mgr.Service) error) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("无法连接Windows服务控制管理器: %v", err)
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(service)
+	if err != nil {
+		return fmt.Errorf("无法打开Windows服务%q: %v", service, err)
+	}
+	defer s.Close()
+
+	return fn(s)
+}
+
+func (w WindowsInitSystem) ServiceStart(service string) error {
+	return w.withService(service, func(s *mgr.Service) error {
+		return s.Start()
+	})
+}
+
+func (w WindowsInitSystem) ServiceStop(service string) error {
+	return w.withService(service, func(s *mgr.Service) error {
+		_, err := s.Control(svc.Stop)
+		return err
+	})
+}
+
+// ServiceRestart依次发送停止、启动请求；SCM没有单独的"restart"动作。停止阶段的错误(例如服务本
+// 来就已经停止)被忽略，只要随后能启动成功就算重启成功。
+func (w WindowsInitSystem) ServiceRestart(service string) error {
+	_ = w.ServiceStop(service)
+	return w.ServiceStart(service)
+}
+
+func (w WindowsInitSystem) ServiceExists(service string) bool {
+	m, err := mgr.Connect()
+	if err != nil {
+		return false
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(service)
+	if err != nil {
+		return false
+	}
+	s.Close()
+	return true
+}
+
+// ServiceIsEnabled对SCM管理的服务等价于ServiceExists: 一旦服务被注册，SCM就会按其启动类型
+// (通常是Automatic)在开机时拉起它，没有独立的"已启用"状态。
+func (w WindowsInitSystem) ServiceIsEnabled(service string) bool {
+	return w.ServiceExists(service)
+}
+
+func (w WindowsInitSystem) ServiceIsActive(service string) bool {
+	err := w.withService(service, func(s *mgr.Service) error {
+		status, err := s.Query()
+		if err != nil {
+			return err
+		}
+		if status.State != svc.Running {
+			return fmt.Errorf("服务当前状态为%v，不是Running", status.State)
+		}
+		return nil
+	})
+	return err == nil
+}
+
+func (w WindowsInitSystem) EnableCommand(service string) string {
+	return fmt.Sprintf("sc.exe config %s start=auto", service)
+}
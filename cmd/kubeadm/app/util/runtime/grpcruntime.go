@@ -0,0 +1,229 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"k8s.io/kubernetes/cmd/kubeadm/app/constants"
+
+	errorsutil "k8s.io/apimachinery/pkg/util/errors"
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
+
+	"github.com/pkg/errors"
+)
+
+// grpcDialTimeout是拨号CRI gRPC套接字允许耗费的最长时长。与kubelet自身连接CRI运行时时使用的
+// 超时量级保持一致，足够覆盖containerd/CRI-O冷启动的情况，又不至于让一次明显无法连接的套接字
+// 拖慢NewContainerRuntime的探测。
+const grpcDialTimeout = 5 * time.Second
+
+// grpcCallTimeout是单次CRI RPC调用(ListPodSandbox、PullImage等)允许耗费的最长时长。
+const grpcCallTimeout = 2 * time.Minute
+
+// GRPCCRIRuntime直接通过CRI套接字拨号, 使用kubelet自身与containerd/CRI-O通信时所用的同一套
+// RuntimeService/ImageService gRPC接口, 取代此前CRIRuntime对crictl的Command/CombinedOutput
+// shell-out: 不再需要让用户安装crictl, 返回的错误也能带上CRI本身报告的Pod ID、镜像摘要等结构化
+// 信息, 而不是一段拼接出来的命令输出字符串。
+type GRPCCRIRuntime struct {
+	criSocket string
+	conn      *grpc.ClientConn
+	runtime   runtimeapi.RuntimeServiceClient
+	image     runtimeapi.ImageServiceClient
+}
+
+// NewGRPCCRIRuntime拨号criSocket(形如"unix:///run/containerd/containerd.sock")并返回一个
+// GRPCCRIRuntime。拨号失败时返回的错误指明criSocket，便于NewContainerRuntime决定是否回退到
+// 基于crictl的CRIRuntime。
+func NewGRPCCRIRuntime(criSocket string) (*GRPCCRIRuntime, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), grpcDialTimeout)
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, criSocket,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithBlock(),
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "无法拨号CRI套接字 %s", criSocket)
+	}
+
+	runtime := &GRPCCRIRuntime{
+		criSocket: criSocket,
+		conn:      conn,
+		runtime:   runtimeapi.NewRuntimeServiceClient(conn),
+		image:     runtimeapi.NewImageServiceClient(conn),
+	}
+
+	// 用Version握手校验对端确实在说CRI协议，而不仅仅是gRPC端口恰好能连上。
+	callCtx, callCancel := context.WithTimeout(context.Background(), grpcCallTimeout)
+	defer callCancel()
+	if _, err := runtime.runtime.Version(callCtx, &runtimeapi.VersionRequest{}); err != nil {
+		conn.Close()
+		return nil, errors.Wrapf(err, "CRI套接字 %s 未响应版本握手", criSocket)
+	}
+
+	return runtime, nil
+}
+
+// IsDocker 如果运行库是docker，则返回true
+func (runtime *GRPCCRIRuntime) IsDocker() bool {
+	return false
+}
+
+// IsRunning 检查运行时是否正在运行
+func (runtime *GRPCCRIRuntime) IsRunning() error {
+	ctx, cancel := context.WithTimeout(context.Background(), grpcCallTimeout)
+	defer cancel()
+	if _, err := runtime.runtime.Version(ctx, &runtimeapi.VersionRequest{}); err != nil {
+		return errors.Wrapf(err, "容器运行时 %s 未运行", runtime.criSocket)
+	}
+	return nil
+}
+
+// HealthCheck依次以Version、ListPodSandbox、ImageFsInfo探测运行时，每一步都带有比IsRunning更窄
+// 的超时；IsRunning只确认Version这一次RPC能响应，卡在relist循环里的containerd仍然会让它通过。
+// 任意一步超过自己的超时预算都判定为运行时卡死，返回RuntimeStalledError并指明是哪一步。
+func (runtime *GRPCCRIRuntime) HealthCheck(ctx context.Context) error {
+	versionCtx, cancel := context.WithTimeout(ctx, healthCheckVersionTimeout)
+	_, err := runtime.runtime.Version(versionCtx, &runtimeapi.VersionRequest{})
+	versionErr := versionCtx.Err()
+	cancel()
+	if versionErr == context.DeadlineExceeded {
+		return &RuntimeStalledError{RPC: "Version"}
+	}
+	if err != nil {
+		return errors.Wrapf(err, "容器运行时健康检查在 Version 这一步失败")
+	}
+
+	listCtx, cancel := context.WithTimeout(ctx, healthCheckListTimeout)
+	_, err = runtime.runtime.ListPodSandbox(listCtx, &runtimeapi.ListPodSandboxRequest{})
+	listErr := listCtx.Err()
+	cancel()
+	if listErr == context.DeadlineExceeded {
+		return &RuntimeStalledError{RPC: "ListPodSandbox"}
+	}
+	if err != nil {
+		return errors.Wrapf(err, "容器运行时健康检查在 ListPodSandbox 这一步失败")
+	}
+
+	imageFsCtx, cancel := context.WithTimeout(ctx, healthCheckImageFsTimeout)
+	_, err = runtime.image.ImageFsInfo(imageFsCtx, &runtimeapi.ImageFsInfoRequest{})
+	imageFsErr := imageFsCtx.Err()
+	cancel()
+	if imageFsErr == context.DeadlineExceeded {
+		return &RuntimeStalledError{RPC: "ImageFsInfo"}
+	}
+	if err != nil {
+		return errors.Wrapf(err, "容器运行时健康检查在 ImageFsInfo 这一步失败")
+	}
+
+	return nil
+}
+
+// ListKubeContainers通过ListPodSandbox列出由kubelet创建的所有Pod沙箱ID。
+func (runtime *GRPCCRIRuntime) ListKubeContainers() ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), grpcCallTimeout)
+	defer cancel()
+
+	resp, err := runtime.runtime.ListPodSandbox(ctx, &runtimeapi.ListPodSandboxRequest{})
+	if err != nil {
+		return nil, errors.Wrap(err, "无法列出Pod沙箱")
+	}
+
+	pods := make([]string, 0, len(resp.Items))
+	for _, item := range resp.Items {
+		pods = append(pods, item.Id)
+	}
+	return pods, nil
+}
+
+// RemoveContainers依次对每个Pod沙箱调用StopPodSandbox再RemovePodSandbox，尽量移除尽可能多的
+// 容器，单个沙箱的失败不会中止其余沙箱的清理，与基于crictl的实现保持一致的"尽力而为"语义。
+func (runtime *GRPCCRIRuntime) RemoveContainers(containers []string) error {
+	var errs []error
+	for _, podID := range containers {
+		ctx, cancel := context.WithTimeout(context.Background(), grpcCallTimeout)
+		_, err := runtime.runtime.StopPodSandbox(ctx, &runtimeapi.StopPodSandboxRequest{PodSandboxId: podID})
+		cancel()
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "无法停止Pod沙箱 %s", podID))
+			continue
+		}
+
+		ctx, cancel = context.WithTimeout(context.Background(), grpcCallTimeout)
+		_, err = runtime.runtime.RemovePodSandbox(ctx, &runtimeapi.RemovePodSandboxRequest{PodSandboxId: podID})
+		cancel()
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "无法移除Pod沙箱 %s", podID))
+		}
+	}
+	return errorsutil.NewAggregate(errs)
+}
+
+// PullImage通过ImageService.PullImage拉取image，返回的错误中包含CRI报告的原始错误，
+// 而不是一段拼接出来的crictl命令输出。
+func (runtime *GRPCCRIRuntime) PullImage(image string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), grpcCallTimeout)
+	defer cancel()
+
+	_, err := runtime.image.PullImage(ctx, &runtimeapi.PullImageRequest{
+		Image: &runtimeapi.ImageSpec{Image: image},
+	})
+	if err != nil {
+		return errors.Wrapf(err, "无法拉取镜像 %s", image)
+	}
+	return nil
+}
+
+// PullImages并发拉取images，parallelism控制同时进行的worker数量。
+func (runtime *GRPCCRIRuntime) PullImages(images []string, parallelism int) error {
+	return pullImagesConcurrently(runtime.PullImage, images, parallelism)
+}
+
+// ImageExists通过ImageStatus查询image在本地是否已经存在，返回的布尔值直接来自CRI的响应，
+// 不再依赖对crictl inspecti退出码的猜测。
+func (runtime *GRPCCRIRuntime) ImageExists(image string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), grpcCallTimeout)
+	defer cancel()
+
+	resp, err := runtime.image.ImageStatus(ctx, &runtimeapi.ImageStatusRequest{
+		Image: &runtimeapi.ImageSpec{Image: image},
+	})
+	if err != nil {
+		return false, errors.Wrapf(err, "无法查询镜像 %s 的状态", image)
+	}
+	return resp.Image != nil, nil
+}
+
+// Close关闭底层的gRPC连接。
+func (runtime *GRPCCRIRuntime) Close() error {
+	return runtime.conn.Close()
+}
+
+// isGRPCEligibleSocket返回criSocket是否是一个可以尝试用gRPC直连的非Docker CRI端点:
+// Docker本身走cri-dockerd这一层，继续使用crictl/docker shell-out路径更可靠，其余以
+// unix://或npipe://开头(或是一个裸露的绝对路径，稍后会被NewContainerRuntime规范化为unix://)
+// 的端点都可以尝试直连。
+func isGRPCEligibleSocket(criSocket string) bool {
+	return criSocket != constants.DefaultDockerCRISocket &&
+		(strings.HasPrefix(criSocket, "unix://") || strings.HasPrefix(criSocket, "npipe://"))
+}
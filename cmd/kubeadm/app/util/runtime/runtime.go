@@ -17,9 +17,14 @@ limitations under the License.
 package util
 
 import (
+	"context"
+	"fmt"
+	"math/rand"
 	"path/filepath"
 	goruntime "runtime"
 	"strings"
+	"sync"
+	"time"
 
 	"k8s.io/kubernetes/cmd/kubeadm/app/constants"
 
@@ -29,16 +34,53 @@ import (
 	"github.com/pkg/errors"
 )
 
+// pullImageMaxAttempts是pullImagesConcurrently对单张镜像的最大尝试次数(含首次尝试)。
+const pullImageMaxAttempts = 4
+
+// pullImageBackoffBase、pullImageBackoffCap是pullImagesConcurrently两次重试之间指数退避的
+// 起始时长与上限：第n次重试等待pullImageBackoffBase*2^(n-1)(再叠加±20%的抖动)，不超过
+// pullImageBackoffCap。
+const (
+	pullImageBackoffBase = 2 * time.Second
+	pullImageBackoffCap  = 30 * time.Second
+)
+
 // ContainerRuntime 是一个使用容器运行时的接口
 type ContainerRuntime interface {
 	IsDocker() bool
 	IsRunning() error
+	HealthCheck(ctx context.Context) error
 	ListKubeContainers() ([]string, error)
 	RemoveContainers(containers []string) error
 	PullImage(image string) error
+	PullImages(images []string, parallelism int) error
 	ImageExists(image string) (bool, error)
 }
 
+// healthCheckVersionTimeout、healthCheckListTimeout、healthCheckImageFsTimeout是HealthCheck
+// 依次探测Version、ListPodSandbox(列出容器/Pod)、ImageFsInfo(或其等价信息)这三步各自allowed
+// 的最长时长。IsRunning只确认守护进程本身能响应一次请求，卡在relist循环里、仍然监听套接字的
+// containerd会被误判为"运行中"；HealthCheck用更窄的超时依次探测这条调用链上真正会被kubelet
+// 用到的几类RPC，命中超时即可判定运行时已经卡死，而不必等到后面的waitForTLSBootstrappedClient
+// 才报出一个无从下手的"超时"。
+const (
+	healthCheckVersionTimeout = 2 * time.Second
+	healthCheckListTimeout    = 3 * time.Second
+	healthCheckImageFsTimeout = 3 * time.Second
+)
+
+// RuntimeStalledError表示HealthCheck探测链路中的某一步超过了自己的超时预算。它区别于IsRunning
+// 返回的普通error：命名了具体卡在哪一步，便于使用方在日志里直接指向问题所在，而不是一段笼统的
+// "容器运行时未运行"。
+type RuntimeStalledError struct {
+	// RPC是探测卡住的那一步，例如"Version"、"ListPodSandbox"、"ImageFsInfo"
+	RPC string
+}
+
+func (e *RuntimeStalledError) Error() string {
+	return fmt.Sprintf("容器运行时探测在 %s 这一步超时，运行时可能已经卡死而不仅仅是未运行", e.RPC)
+}
+
 // CRIRuntime 是一个与CRI接口的结构
 type CRIRuntime struct {
 	exec      utilsexec.Interface
@@ -62,7 +104,22 @@ func NewContainerRuntime(execer utilsexec.Interface, criSocket string) (Containe
 		// 如果CRI Socket文件的路径是没问题的并且运行在非Windows系统上就返回unix://协议的链接
 		if filepath.IsAbs(criSocket) && goruntime.GOOS != "windows" {
 			criSocket = "unix://" + criSocket
+		} else if goruntime.GOOS == "windows" && !strings.HasPrefix(criSocket, "npipe://") {
+			// Windows上kubelet与CRI运行时之间走命名管道而不是Unix域套接字，自动补上
+			// npipe://前缀，这样用户传入裸露的管道路径(或使用未加前缀的内置默认值)时，
+			// 既能被crictl -r正确识别，也能被GRPCCRIRuntime直接拨号。
+			criSocket = "npipe://" + criSocket
+		}
+
+		// 对于非Docker端点，优先直接拨号CRI gRPC接口，这样就不再需要用户额外安装crictl，
+		// 报出的错误也能带上CRI本身的结构化信息。只有在gRPC拨号失败、且crictl二进制文件
+		// 存在时，才回退到此前基于shell-out的CRIRuntime，保持在旧环境下的兼容性。
+		if isGRPCEligibleSocket(criSocket) {
+			if grpcRuntime, err := NewGRPCCRIRuntime(criSocket); err == nil {
+				return grpcRuntime, nil
+			}
 		}
+
 		runtime = &CRIRuntime{execer, criSocket}
 	} else {
 		toolName = "docker"
@@ -94,6 +151,35 @@ func (runtime *CRIRuntime) IsRunning() error {
 	return nil
 }
 
+// HealthCheck依次以crictl version、crictl pods -q、crictl imagefsinfo探测运行时，每一步都带有
+// 比IsRunning更窄的超时；任意一步超过自己的超时预算都判定为运行时卡死，返回RuntimeStalledError
+// 并指明是哪一步。
+func (runtime *CRIRuntime) HealthCheck(ctx context.Context) error {
+	steps := []struct {
+		rpc     string
+		args    []string
+		timeout time.Duration
+	}{
+		{"Version", []string{"-r", runtime.criSocket, "version"}, healthCheckVersionTimeout},
+		{"ListPodSandbox", []string{"-r", runtime.criSocket, "pods", "-q"}, healthCheckListTimeout},
+		{"ImageFsInfo", []string{"-r", runtime.criSocket, "imagefsinfo"}, healthCheckImageFsTimeout},
+	}
+
+	for _, step := range steps {
+		stepCtx, cancel := context.WithTimeout(ctx, step.timeout)
+		out, err := runtime.exec.CommandContext(stepCtx, "crictl", step.args...).CombinedOutput()
+		stepErr := stepCtx.Err()
+		cancel()
+		if stepErr == context.DeadlineExceeded {
+			return &RuntimeStalledError{RPC: step.rpc}
+		}
+		if err != nil {
+			return errors.Wrapf(err, "容器运行时健康检查在 %s 这一步失败: 输出: %s", step.rpc, string(out))
+		}
+	}
+	return nil
+}
+
 // IsRunning 检查运行时是否正在运行
 func (runtime *DockerRuntime) IsRunning() error {
 	if out, err := runtime.exec.Command("docker", "info").CombinedOutput(); err != nil {
@@ -102,6 +188,35 @@ func (runtime *DockerRuntime) IsRunning() error {
 	return nil
 }
 
+// HealthCheck依次以docker version、docker ps -q、docker info(docker没有CRI那样单独的
+// ImageFsInfo调用，借用info里同样会报告的存储驱动/磁盘占用信息作为等价探测)探测运行时，
+// 任意一步超过自己的超时预算都判定为运行时卡死，返回RuntimeStalledError并指明是哪一步。
+func (runtime *DockerRuntime) HealthCheck(ctx context.Context) error {
+	steps := []struct {
+		rpc     string
+		args    []string
+		timeout time.Duration
+	}{
+		{"Version", []string{"version"}, healthCheckVersionTimeout},
+		{"ListPodSandbox", []string{"ps", "-q"}, healthCheckListTimeout},
+		{"ImageFsInfo", []string{"info"}, healthCheckImageFsTimeout},
+	}
+
+	for _, step := range steps {
+		stepCtx, cancel := context.WithTimeout(ctx, step.timeout)
+		out, err := runtime.exec.CommandContext(stepCtx, "docker", step.args...).CombinedOutput()
+		stepErr := stepCtx.Err()
+		cancel()
+		if stepErr == context.DeadlineExceeded {
+			return &RuntimeStalledError{RPC: step.rpc}
+		}
+		if err != nil {
+			return errors.Wrapf(err, "容器运行时健康检查在 %s 这一步失败: 输出: %s", step.rpc, string(out))
+		}
+	}
+	return nil
+}
+
 // ListKubeContainers lists running k8s CRI pods
 func (runtime *CRIRuntime) ListKubeContainers() ([]string, error) {
 	out, err := runtime.exec.Command("crictl", "-r", runtime.criSocket, "pods", "-q").CombinedOutput()
@@ -168,6 +283,11 @@ func (runtime *CRIRuntime) PullImage(image string) error {
 	return errors.Wrapf(err, "output: %s, error", out)
 }
 
+// PullImages并发拉取images，parallelism控制同时进行的worker数量。
+func (runtime *CRIRuntime) PullImages(images []string, parallelism int) error {
+	return pullImagesConcurrently(runtime.PullImage, images, parallelism)
+}
+
 // PullImage pulls the image
 func (runtime *DockerRuntime) PullImage(image string) error {
 	var err error
@@ -181,6 +301,11 @@ func (runtime *DockerRuntime) PullImage(image string) error {
 	return errors.Wrapf(err, "output: %s, error", out)
 }
 
+// PullImages并发拉取images，parallelism控制同时进行的worker数量。
+func (runtime *DockerRuntime) PullImages(images []string, parallelism int) error {
+	return pullImagesConcurrently(runtime.PullImage, images, parallelism)
+}
+
 // ImageExists checks to see if the image exists on the system
 func (runtime *CRIRuntime) ImageExists(image string) (bool, error) {
 	err := runtime.exec.Command("crictl", "-r", runtime.criSocket, "inspecti", image).Run()
@@ -196,10 +321,8 @@ func (runtime *DockerRuntime) ImageExists(image string) (bool, error) {
 // detectCRISocketImpl is separated out only for test purposes, DON'T call it directly, use DetectCRISocket instead
 func detectCRISocketImpl(isSocket func(string) bool) (string, error) {
 	foundCRISockets := []string{}
-	knownCRISockets := []string{
-		// Docker and containerd sockets are special cased below, hence not to be included here
-		"/var/run/crio/crio.sock",
-	}
+	// extraKnownCRISockets列出了dockerSocket/containerdSocket之外、按平台探测的其余已知CRI端点，
+	// 在runtime_unix.go/runtime_windows.go中分别定义。
 
 	if isSocket(dockerSocket) {
 		// the path in dockerSocket is not CRI compatible, hence we should replace it with a CRI compatible socket
@@ -210,7 +333,7 @@ func detectCRISocketImpl(isSocket func(string) bool) (string, error) {
 		foundCRISockets = append(foundCRISockets, containerdSocket)
 	}
 
-	for _, socket := range knownCRISockets {
+	for _, socket := range extraKnownCRISockets {
 		if isSocket(socket) {
 			foundCRISockets = append(foundCRISockets, socket)
 		}
@@ -233,3 +356,85 @@ func detectCRISocketImpl(isSocket func(string) bool) (string, error) {
 func DetectCRISocket() (string, error) {
 	return detectCRISocketImpl(isExistingSocket)
 }
+
+// pullImagesConcurrently用pull(通常是某个ContainerRuntime实现的PullImage方法)并发拉取images，
+// 分摊到最多parallelism个worker上(parallelism<=0时按min(4, len(images))处理)；每张镜像拉取失败后
+// 按指数退避(pullImageBackoffBase起步，2倍递增，叠加±20%抖动，pullImageBackoffCap封顶)重试，
+// 直至成功或用尽pullImageMaxAttempts次尝试。每张镜像拉取成功都会打印一行耗时日志，所有失败通过
+// errorsutil.NewAggregate聚合后一并返回，不会让前面几张镜像的失败掩盖后面的。
+func pullImagesConcurrently(pull func(string) error, images []string, parallelism int) error {
+	if parallelism <= 0 {
+		parallelism = 4
+	}
+	if parallelism > len(images) {
+		parallelism = len(images)
+	}
+	if parallelism <= 0 {
+		return nil
+	}
+
+	jobs := make(chan string)
+	results := make(chan error, len(images))
+	var wg sync.WaitGroup
+
+	for i := 0; i < parallelism; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for image := range jobs {
+				results <- pullOneImageWithBackoff(pull, image)
+			}
+		}()
+	}
+
+	go func() {
+		for _, image := range images {
+			jobs <- image
+		}
+		close(jobs)
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var errs []error
+	for i := 0; i < len(images); i++ {
+		if err := <-results; err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errorsutil.NewAggregate(errs)
+}
+
+// pullOneImageWithBackoff拉取单张镜像，在失败时按指数退避重试，直至成功或用尽
+// pullImageMaxAttempts次尝试；成功时打印一行带耗时的进度日志。
+func pullOneImageWithBackoff(pull func(string) error, image string) error {
+	start := time.Now()
+	var lastErr error
+	for attempt := 0; attempt < pullImageMaxAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(pullBackoffDuration(attempt))
+		}
+
+		err := pull(image)
+		if err == nil {
+			fmt.Printf("[pull] %s … done in %s\n", image, time.Since(start).Round(100*time.Millisecond))
+			return nil
+		}
+		lastErr = err
+	}
+	return errors.Wrapf(lastErr, "无法拉取镜像 %s", image)
+}
+
+// pullBackoffDuration计算第attempt次重试前应等待的时长：pullImageBackoffBase*2^(attempt-1)，
+// 叠加±20%的抖动，并以pullImageBackoffCap为上限。
+func pullBackoffDuration(attempt int) time.Duration {
+	backoff := pullImageBackoffBase * time.Duration(int64(1)<<uint(attempt-1))
+	if backoff > pullImageBackoffCap {
+		backoff = pullImageBackoffCap
+	}
+	jitter := 0.8 + 0.4*rand.Float64()
+	return time.Duration(float64(backoff) * jitter)
+}
@@ -0,0 +1,43 @@
+//go:build !windows
+
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import "net"
+
+// dockerSocket是dockershim在类Unix系统上监听的socket路径。
+const dockerSocket = "/var/run/dockershim.sock"
+
+// containerdSocket是containerd在类Unix系统上默认监听的socket路径。
+const containerdSocket = "/run/containerd/containerd.sock"
+
+// extraKnownCRISockets列出了dockerSocket/containerdSocket之外，在类Unix系统上还会探测的
+// 已知CRI端点。
+var extraKnownCRISockets = []string{
+	"/var/run/crio/crio.sock",
+}
+
+// isExistingSocket通过尝试以unix协议拨号path来判断该路径上是否有进程正在监听一个Unix域套接字。
+func isExistingSocket(path string) bool {
+	c, err := net.Dial("unix", path)
+	if err != nil {
+		return false
+	}
+	c.Close()
+	return true
+}
@@ -0,0 +1,61 @@
+//go:build windows
+
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"strings"
+
+	"golang.org/x/sys/windows"
+)
+
+// dockerSocket是dockershim在Windows上监听的命名管道路径。
+const dockerSocket = `npipe:////./pipe/dockershim`
+
+// containerdSocket是containerd在Windows上默认监听的命名管道路径。
+const containerdSocket = `npipe:////./pipe/containerd-containerd`
+
+// extraKnownCRISockets在Windows上没有额外已知CRI端点需要探测: CRI-O不支持Windows，
+// 因此留空，与类Unix系统上还会探测/var/run/crio/crio.sock形成对照。
+var extraKnownCRISockets []string
+
+// isExistingSocket通过CreateFile尝试打开path(去掉"npipe://"前缀后的命名管道路径)来判断是否有
+// 进程正在监听这个命名管道；Windows没有Unix域套接字，kubelet与CRI运行时之间改用命名管道通信。
+func isExistingSocket(path string) bool {
+	pipePath := strings.TrimPrefix(path, "npipe://")
+
+	pipePathUTF16, err := windows.UTF16PtrFromString(pipePath)
+	if err != nil {
+		return false
+	}
+
+	handle, err := windows.CreateFile(
+		pipePathUTF16,
+		windows.GENERIC_READ,
+		0,
+		nil,
+		windows.OPEN_EXISTING,
+		0,
+		0,
+	)
+	if err != nil {
+		return false
+	}
+	windows.CloseHandle(handle)
+	return true
+}
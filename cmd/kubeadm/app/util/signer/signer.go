@@ -0,0 +1,142 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package signer是certs阶段在--signer-socket被设置时使用的外部签名器客户端：它不在本地保存CA
+// 私钥，而是把本地生成的CSR通过一个Unix域套接字发送给外部插件(KMS、PKCS#11 HSM、Vault等)签名，
+// 插件以同样的socket约定作为服务端监听，与kube-apiserver的KMS provider插件socket是同一套思路，
+// 但这里使用的是一套单行JSON请求/响应协议，而不是一份完整的gRPC/protobuf服务定义。
+package signer
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Type标识外部签名器插件的后端种类。
+type Type string
+
+const (
+	// KMS表示插件把签名操作委托给一个密钥管理服务。
+	KMS Type = "kms"
+	// PKCS11表示插件把签名操作委托给一个PKCS#11 HSM。
+	PKCS11 Type = "pkcs11"
+	// Vault表示插件把签名操作委托给HashiCorp Vault的PKI secrets引擎。
+	Vault Type = "vault"
+)
+
+// dialTimeout是连接插件socket允许耗费的时长。
+const dialTimeout = 5 * time.Second
+
+// Config描述如何连接一个外部签名器插件。
+type Config struct {
+	// Type是插件的后端种类，取值为KMS、PKCS11或Vault之一。
+	Type Type
+	// Endpoint是插件监听的Unix域套接字路径。
+	Endpoint string
+}
+
+// Signer是外部签名器插件客户端实现的接口：certs阶段用它代替本地CA私钥完成CSR签名。
+type Signer interface {
+	// Sign把一份PEM编码的CSR发送给插件，返回插件签发的PEM编码证书。
+	Sign(csrPEM []byte) ([]byte, error)
+	// Ping校验插件是否可达，用于"kubeadm init phase certs check-signer"在生成任何证书之前
+	// 提前发现配置错误的插件socket。
+	Ping() error
+}
+
+// request是发往插件socket的单行JSON请求。
+type request struct {
+	Op  string `json:"op"`
+	CSR string `json:"csr,omitempty"`
+}
+
+// response是插件socket返回的单行JSON响应。
+type response struct {
+	Error string `json:"error,omitempty"`
+	Cert  string `json:"cert,omitempty"`
+}
+
+// socketSigner是Signer基于Unix域套接字的实现。
+type socketSigner struct {
+	cfg Config
+}
+
+// New按cfg创建一个外部签名器客户端；cfg.Type不是已知的后端种类或cfg.Endpoint为空时返回错误。
+func New(cfg Config) (Signer, error) {
+	switch cfg.Type {
+	case KMS, PKCS11, Vault:
+	default:
+		return nil, errors.Errorf("未知的外部签名器类型 %q，可用的取值有: kms, pkcs11, vault", cfg.Type)
+	}
+	if cfg.Endpoint == "" {
+		return nil, errors.New("外部签名器的socket路径不能为空")
+	}
+	return &socketSigner{cfg: cfg}, nil
+}
+
+// Ping实现Signer。
+func (s *socketSigner) Ping() error {
+	_, err := s.call(request{Op: "ping"})
+	return err
+}
+
+// Sign实现Signer。
+func (s *socketSigner) Sign(csrPEM []byte) ([]byte, error) {
+	resp, err := s.call(request{Op: "sign", CSR: string(csrPEM)})
+	if err != nil {
+		return nil, err
+	}
+	return []byte(resp.Cert), nil
+}
+
+// call拨号到插件的socket, 发送一行JSON编码的req, 并读取一行JSON编码的响应。
+func (s *socketSigner) call(req request) (*response, error) {
+	conn, err := net.DialTimeout("unix", s.cfg.Endpoint, dialTimeout)
+	if err != nil {
+		return nil, errors.Wrapf(err, "无法连接外部签名器插件 %q", s.cfg.Endpoint)
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(dialTimeout)); err != nil {
+		return nil, errors.Wrap(err, "无法为外部签名器插件连接设置超时时间")
+	}
+
+	raw, err := json.Marshal(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "无法序列化外部签名器请求")
+	}
+	if _, err := conn.Write(append(raw, '\n')); err != nil {
+		return nil, errors.Wrapf(err, "无法向外部签名器插件 %q 写入请求", s.cfg.Endpoint)
+	}
+
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err != nil {
+		return nil, errors.Wrapf(err, "无法从外部签名器插件 %q 读取响应", s.cfg.Endpoint)
+	}
+
+	resp := &response{}
+	if err := json.Unmarshal(line, resp); err != nil {
+		return nil, errors.Wrapf(err, "无法解析外部签名器插件 %q 的响应", s.cfg.Endpoint)
+	}
+	if resp.Error != "" {
+		return nil, errors.Errorf("外部签名器插件 %q 返回错误: %s", s.cfg.Endpoint, resp.Error)
+	}
+	return resp, nil
+}